@@ -17,7 +17,7 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.13.0
 	github.com/golang-sql/civil v0.0.0-20190719163853-cb61b32ac6fe
 	github.com/golang/protobuf v1.4.2
-	github.com/google/go-cmp v0.5.2
+	github.com/google/go-cmp v0.5.6
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.0.0-beta.5
 	github.com/hashicorp/boundary/api v0.0.1
 	github.com/hashicorp/boundary/sdk v0.0.1
@@ -33,6 +33,7 @@ require (
 	github.com/hashicorp/shared-secure-libs v0.0.2
 	github.com/hashicorp/vault/sdk v0.1.14-0.20200916184745-5576096032f8
 	github.com/iancoleman/strcase v0.1.2
+	github.com/jackc/pgconn v1.7.0
 	github.com/jackc/pgx/v4 v4.9.0
 	github.com/jinzhu/gorm v1.9.16
 	github.com/kr/pretty v0.2.1
@@ -49,8 +50,11 @@ require (
 	github.com/pires/go-proxyproto v0.2.0
 	github.com/pkg/errors v0.9.1
 	github.com/posener/complete v1.2.3
-	github.com/stretchr/testify v1.6.1
+	github.com/stretchr/testify v1.7.0
 	github.com/zalando/go-keyring v0.1.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
 	go.uber.org/atomic v1.7.0
 	golang.org/x/crypto v0.0.0-20201002170205-7f63de1d35b0
 	golang.org/x/tools v0.0.0-20201009032223-96877f285f7e
@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"errors"
+	"time"
+)
+
+// APIError is the error envelope returned by Boundary's API.
+type APIError struct {
+	// Status is the HTTP status code (see HTTPStatus).
+	Status int `json:"status"`
+
+	// Code is the symbolic name of the error's Code (for example
+	// "NotUnique").
+	Code string `json:"code"`
+
+	// Message is safe to return to end users (see UserFacingMessage).
+	Message string `json:"message"`
+
+	// Details are the optional Details of the error.
+	Details map[string]string `json:"details,omitempty"`
+
+	// RetryAfter is the optional number of seconds to wait before retrying
+	// the request (see GetRetryAfter), rounded up to a whole second.
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// ToAPIError returns the APIError for the first *Err in the error's chain.
+// Errors which don't contain an *Err are returned as an Unknown error, so
+// their messages aren't returned to end users.  Nil is returned for nil.
+func ToAPIError(err error) *APIError {
+	if err == nil {
+		return nil
+	}
+	var e *Err
+	if !errors.As(err, &e) {
+		e = &Err{Code: Unknown}
+	}
+	apiErr := &APIError{
+		Status:  HTTPStatus(e),
+		Code:    e.Code.String(),
+		Message: e.UserFacingMessage(),
+	}
+	if d := GetRetryAfter(err); d > 0 {
+		apiErr.RetryAfter = int((d + time.Second - 1) / time.Second)
+	}
+	if len(e.Details) > 0 {
+		apiErr.Details = make(map[string]string, len(e.Details))
+		for k, v := range e.Details {
+			apiErr.Details[k] = v
+		}
+	}
+	return apiErr
+}
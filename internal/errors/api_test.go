@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToAPIError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want *APIError
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("secret"),
+			want: &APIError{Status: http.StatusInternalServerError, Code: "Unknown", Message: "unknown"},
+		},
+		{
+			name: "invalid-parameter",
+			err:  New(InvalidParameter, WithOp("alice.Bob"), WithMsg("missing name"), WithoutStack()),
+			want: &APIError{Status: http.StatusBadRequest, Code: "InvalidParameter", Message: "missing name"},
+		},
+		{
+			name: "not-found",
+			err:  fmt.Errorf("wrapped: %w", New(RecordNotFound, WithoutStack())),
+			want: &APIError{Status: http.StatusNotFound, Code: "RecordNotFound", Message: "record not found"},
+		},
+		{
+			name: "not-unique",
+			err:  New(NotUnique, WithRedactedMsg("Key (name)=(alice) already exists."), WithDetails(map[string]string{"name": "alice"}), WithoutStack()),
+			want: &APIError{Status: http.StatusConflict, Code: "NotUnique", Message: "Key (name)=(alice) already exists.", Details: map[string]string{"name": "alice"}},
+		},
+		{
+			name: "http-status",
+			err:  New(InvalidParameter, WithMsg("forbidden"), WithHTTPStatus(http.StatusForbidden), WithoutStack()),
+			want: &APIError{Status: http.StatusForbidden, Code: "InvalidParameter", Message: "forbidden"},
+		},
+		{
+			name: "timeout",
+			err:  New(Timeout, WithWrap(errors.New("secret")), WithoutStack()),
+			want: &APIError{Status: http.StatusInternalServerError, Code: "Timeout", Message: "timeout"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, ToAPIError(tt.err))
+		})
+	}
+	t.Run("retry-after", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(TransactionRetryable, WithRetryAfter(1500*time.Millisecond), WithoutStack())
+		assert.Equal(2, ToAPIError(err).RetryAfter)
+		assert.Equal(5, ToAPIError(Wrap(New(Timeout, WithRetryAfter(5*time.Second)), "alice.Bob", Unknown)).RetryAfter)
+		assert.Zero(ToAPIError(New(TransactionRetryable, WithoutStack())).RetryAfter)
+	})
+	t.Run("details-copied", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithDetails(map[string]string{"name": "alice"}), WithoutStack()).(*Err)
+		ToAPIError(err).Details["name"] = "eve"
+		assert.Equal("alice", err.Details["name"])
+	})
+}
@@ -0,0 +1,66 @@
+package errors
+
+// Builder builds an Err with chainable setters, as an alternative to New's
+// options:
+//
+//	return errors.Build().Code(errors.NotUnique).Op(op).Msg("name is already in use").Wrap(err).Err()
+//
+// The setters are equivalent to New's options (Msg is WithMsg, Wrap is
+// WithWrap, etc) and later setters override earlier ones in the same way.  A
+// Builder may be reused, since each call to Err creates an independent Err.
+// The zero value builds an Unknown Err.
+type Builder struct {
+	code Code
+	opts []Option
+}
+
+// Build returns a new Builder.
+func Build() *Builder {
+	return &Builder{}
+}
+
+// Code sets the Err's Code.
+func (b *Builder) Code(c Code) *Builder {
+	b.code = c
+	return b
+}
+
+// Op sets the Err's Op (see WithOp).
+func (b *Builder) Op(op Op) *Builder {
+	return b.With(WithOp(op))
+}
+
+// Msg sets the Err's Msg (see WithMsg).
+func (b *Builder) Msg(msg string) *Builder {
+	return b.With(WithMsg(msg))
+}
+
+// Msgf sets the Err's Msg to a formatted message (see WithMsgf).
+func (b *Builder) Msgf(format string, args ...interface{}) *Builder {
+	return b.With(WithMsgf(format, args...))
+}
+
+// Wrap sets the error the Err wraps (see WithWrap).
+func (b *Builder) Wrap(err error) *Builder {
+	return b.With(WithWrap(err))
+}
+
+// Details adds to the Err's Details (see WithDetails).
+func (b *Builder) Details(kv map[string]string) *Builder {
+	return b.With(WithDetails(kv))
+}
+
+// With adds any of New's options, for settings without their own setter.
+func (b *Builder) With(opt ...Option) *Builder {
+	b.opts = append(b.opts, opt...)
+	return b
+}
+
+// Err creates a new Err from the Builder's settings, like New.
+func (b *Builder) Err() error {
+	// copy the options, so the Builder can keep being modified and reused
+	opts := make([]Option, len(b.opts))
+	copy(opts, b.opts)
+	// skip runtime.Callers, callers, newErr and Err
+	return newErr(4, b.code, opts...)
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("test error")
+	tests := []struct {
+		name string
+		b    *Builder
+		want error
+	}{
+		{
+			name: "zero",
+			b:    &Builder{},
+			want: New(Unknown),
+		},
+		{
+			name: "code",
+			b:    Build().Code(NotUnique),
+			want: New(NotUnique),
+		},
+		{
+			name: "full-chain",
+			b: Build().
+				Code(NotUnique).
+				Op("iam.CreateRole").
+				Msg("name is already in use").
+				Wrap(stdErr).
+				Details(map[string]string{"name": "alice"}).
+				With(WithRequestID("r_1234567890")),
+			want: New(NotUnique, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithWrap(stdErr), WithDetails(map[string]string{"name": "alice"}), WithRequestID("r_1234567890")),
+		},
+		{
+			name: "msgf",
+			b:    Build().Code(InvalidParameter).Msgf("%s is required", "name"),
+			want: New(InvalidParameter, WithMsg("name is required")),
+		},
+		{
+			name: "last-wins",
+			b:    Build().Code(NotUnique).Code(NotNull).Msg("first").Msg("second"),
+			want: New(NotNull, WithMsg("second")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(tt.b.Err()))
+		})
+	}
+	t.Run("independent", func(t *testing.T) {
+		assert := assert.New(t)
+		b := Build().Code(NotUnique).Op("iam.CreateRole").Details(map[string]string{"name": "alice"})
+		first := b.Err().(*Err)
+		second := b.Err().(*Err)
+		assert.NotSame(first, second)
+		assert.Equal(clearVolatile(first), clearVolatile(second))
+
+		first.Add("id", "r_1234567890")
+		assert.Equal(map[string]string{"name": "alice"}, second.Details)
+
+		// modifying the Builder doesn't affect Errs it already built
+		third := b.Msg("test msg").Err().(*Err)
+		assert.Equal("test msg", third.Msg)
+		assert.Empty(second.Msg)
+	})
+	t.Run("stack", func(t *testing.T) {
+		assert := assert.New(t)
+		// the stack starts at the caller of Err
+		err := Build().Code(NotUnique).Err().(*Err)
+		f, _ := runtime.CallersFrames(err.stack).Next()
+		assert.Contains(f.Function, "TestBuilder")
+	})
+}
@@ -0,0 +1,170 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Flatten returns every *Err in the error's chain, outermost first.  It walks
+// the chain by repeatedly unwrapping (depth first through any errors which
+// wrap multiple errors) and skips errors which aren't an *Err.  At most
+// maxChainDepth errors are walked, so a cyclic chain is safe.  An empty slice
+// is returned for nil.
+func Flatten(err error) []*Err {
+	errs := []*Err{}
+	walkChain(err, func(e error) bool {
+		if be, ok := e.(*Err); ok && be != nil {
+			errs = append(errs, be)
+		}
+		return true
+	})
+	return errs
+}
+
+// FullOp returns the Ops of every *Err in the error's chain, outermost first
+// and joined by ": " (for example "iam.CreateRole: db.Create"), which is the
+// call path of the error.  Errs without an Op are skipped and an empty string
+// is returned when there are no Ops.  See Flatten for how the chain is walked.
+func FullOp(err error) string {
+	var ops []string
+	for _, e := range Flatten(err) {
+		if e.Op != "" {
+			ops = append(ops, string(e.Op))
+		}
+	}
+	return strings.Join(ops, ": ")
+}
+
+// ChainContains returns true when any *Err in the error's chain has the Code
+// c, not just the first one.  See Flatten for how the chain is walked.
+func ChainContains(err error, c Code) bool {
+	found := false
+	walkChain(err, func(e error) bool {
+		if be, ok := e.(*Err); ok && be != nil && be.Code == c {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Cause returns the deepest error in the error's chain, which is its root
+// cause, by repeatedly unwrapping it until it reaches an error which doesn't
+// wrap another.  An *Err with a Cause (see WithCause) is followed to its Cause
+// rather than its Wrapped error, since the Cause is its explicit root cause,
+// and for an error which wraps multiple errors, the first of them is followed.
+// At most maxChainDepth errors are unwrapped, so a cyclic chain is safe, and
+// nil is returned for nil.
+func Cause(err error) error {
+	for depth := 0; err != nil && depth < maxChainDepth; depth++ {
+		var next error
+		switch w := err.(type) {
+		case *Err:
+			// the Code's sentinel returned by Unwrap isn't a cause, so
+			// only the Wrapped error is followed
+			if w != nil && !isEmptyErr(w.Cause) {
+				next = w.Cause
+			} else if w != nil {
+				next = nilIfEmpty(w.Wrapped)
+			}
+		case interface{ Unwrap() []error }:
+			if errs := w.Unwrap(); len(errs) > 0 {
+				next = errs[0]
+			}
+		case interface{ Unwrap() error }:
+			next = w.Unwrap()
+		}
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+	return err
+}
+
+// firstErr returns the first *Err in the error's chain, or nil if there's
+// none, with the same result as errors.As(err, &e).  Unlike errors.As it
+// doesn't allocate, since the classification functions which use it (GetCode,
+// GetKind, IsRetryable, etc) are called in hot paths such as retry loops.
+// Errors with their own As method are left to errors.As.  At most
+// maxChainDepth errors are walked.
+func firstErr(err error) *Err {
+	e, _ := findErr(err, 0)
+	return e
+}
+
+// findErr returns the first *Err in the error's chain, depth first, and the
+// number of errors walked, which starts from walked.
+func findErr(err error, walked int) (*Err, int) {
+	for ; err != nil && walked < maxChainDepth; walked++ {
+		switch w := err.(type) {
+		case *Err:
+			return w, walked
+		case interface{ As(interface{}) bool }:
+			var e *Err
+			if errors.As(err, &e) {
+				return e, walked
+			}
+			return nil, walked
+		case interface{ Unwrap() []error }:
+			walked++
+			for _, wrapped := range w.Unwrap() {
+				var e *Err
+				if e, walked = findErr(wrapped, walked); e != nil {
+					return e, walked
+				}
+			}
+			return nil, walked
+		case interface{ Unwrap() error }:
+			err = w.Unwrap()
+		default:
+			return nil, walked
+		}
+	}
+	return nil, walked
+}
+
+// firstKnownCode returns the first Code other than Unknown of the Errs in the
+// error's chain, or Unknown if there's none.  See walkChain for how the chain
+// is walked.
+func firstKnownCode(err error) Code {
+	c := Unknown
+	walkChain(err, func(e error) bool {
+		if be, ok := e.(*Err); ok && be != nil {
+			c = be.Code
+		}
+		return c == Unknown
+	})
+	return c
+}
+
+// walkChain calls fn for err and each error in its chain, depth first, until
+// fn returns false.  At most maxChainDepth errors are walked.
+func walkChain(err error, fn func(error) bool) {
+	count := 0
+	var walk func(error) bool
+	walk = func(err error) bool {
+		if err == nil {
+			return true
+		}
+		if count >= maxChainDepth {
+			return false
+		}
+		count++
+		if !fn(err) {
+			return false
+		}
+		switch w := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range w.Unwrap() {
+				if !walk(e) {
+					return false
+				}
+			}
+		case interface{ Unwrap() error }:
+			return walk(w.Unwrap())
+		}
+		return true
+	}
+	walk(err)
+}
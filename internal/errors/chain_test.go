@@ -0,0 +1,305 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+	inner := New(NotUnique, WithOp("db.Create")).(*Err)
+	middle := New(InvalidParameter, WithOp("iam.CreateRole"), WithWrap(fmt.Errorf("wrapped: %w", inner))).(*Err)
+	outer := New(Unknown, WithWrap(middle)).(*Err)
+	multi := New(Unknown, WithWraps(inner, errors.New("test error"), middle)).(*Err)
+
+	tests := []struct {
+		name string
+		err  error
+		want []*Err
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: []*Err{},
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+			want: []*Err{},
+		},
+		{
+			name: "single",
+			err:  inner,
+			want: []*Err{inner},
+		},
+		{
+			name: "mixed",
+			err:  fmt.Errorf("top: %w", outer),
+			want: []*Err{outer, middle, inner},
+		},
+		{
+			name: "multiple-wrapped",
+			err:  multi,
+			want: []*Err{multi, inner, middle, inner},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, Flatten(tt.err))
+		})
+	}
+	t.Run("cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		e := New(InvalidParameter).(*Err)
+		e.Wrapped = e
+		got := Flatten(e)
+		assert.Len(got, maxChainDepth)
+		assert.Equal(e, got[0])
+	})
+}
+
+// asErrError is an error with an As method which provides an *Err.
+type asErrError struct {
+	e *Err
+}
+
+func (a asErrError) Error() string { return "as error" }
+
+func (a asErrError) As(target interface{}) bool {
+	if e, ok := target.(**Err); ok {
+		*e = a.e
+		return true
+	}
+	return false
+}
+
+func TestFirstErr(t *testing.T) {
+	t.Parallel()
+	inner := New(NotUnique, WithOp("db.Create")).(*Err)
+	tests := []struct {
+		name string
+		err  error
+		want *Err
+	}{
+		{
+			name: "nil",
+			err:  nil,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+		},
+		{
+			name: "err",
+			err:  inner,
+			want: inner,
+		},
+		{
+			name: "std-wrapped",
+			err:  fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", inner)),
+			want: inner,
+		},
+		{
+			name: "multiple-wrapped",
+			err:  wrappedErrors{errors.New("test error"), fmt.Errorf("wrapped: %w", inner)},
+			want: inner,
+		},
+		{
+			name: "multiple-wrapped-without-err",
+			err:  fmt.Errorf("wrapped: %w", wrappedErrors{errors.New("test error 1"), errors.New("test error 2")}),
+		},
+		{
+			name: "as",
+			err:  fmt.Errorf("wrapped: %w", asErrError{e: inner}),
+			want: inner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, firstErr(tt.err))
+			// the same result as errors.As
+			var e *Err
+			assert.Equal(tt.want != nil, errors.As(tt.err, &e))
+			assert.Equal(tt.want, e)
+		})
+	}
+	t.Run("cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		var err error = errors.New("test error")
+		for i := 0; i < maxChainDepth; i++ {
+			err = fmt.Errorf("wrapped: %w", err)
+		}
+		assert.Nil(firstErr(fmt.Errorf("wrapped: %w", err)))
+	})
+}
+
+func TestCause(t *testing.T) {
+	t.Parallel()
+	root := errors.New("connection refused")
+	deep := root
+	for i := 0; i < 10; i++ {
+		deep = fmt.Errorf("level %d: %w", i, deep)
+	}
+	rootErr := New(RecordNotFound, WithOp("db.LookupById")).(*Err)
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "std-error",
+			err:  root,
+			want: root,
+		},
+		{
+			name: "deep-std-chain",
+			err:  deep,
+			want: root,
+		},
+		{
+			name: "mixed",
+			err:  New(Unknown, WithOp("iam.CreateRole"), WithWrap(fmt.Errorf("wrapped: %w", New(NotUnique, WithWrap(root))))),
+			want: root,
+		},
+		{
+			name: "err-root",
+			err:  fmt.Errorf("wrapped: %w", New(InvalidParameter, WithWrap(rootErr))),
+			want: rootErr,
+		},
+		{
+			name: "multiple-wrapped",
+			err:  New(Unknown, WithWraps(fmt.Errorf("first: %w", root), errors.New("second"))),
+			want: root,
+		},
+		{
+			name: "err-cause",
+			err:  New(Unknown, WithCause(root), WithWrap(New(InvalidParameter, WithOp("iam.CreateRole")))),
+			want: root,
+		},
+		{
+			name: "nested-err-cause",
+			err:  fmt.Errorf("wrapped: %w", New(Unknown, WithCause(New(NotUnique, WithWrap(deep))))),
+			want: root,
+		},
+		{
+			name: "empty-multiple-wrapped",
+			err:  wrappedErrors{},
+			want: wrappedErrors{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, Cause(tt.err))
+		})
+	}
+	t.Run("cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		e := New(InvalidParameter).(*Err)
+		e.Wrapped = e
+		assert.Equal(e, Cause(e))
+	})
+}
+
+func TestFullOp(t *testing.T) {
+	t.Parallel()
+	db := New(NotUnique, WithOp("db.Create"), WithoutStack())
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+			want: "",
+		},
+		{
+			name: "no-op",
+			err:  New(NotUnique, WithoutStack()),
+			want: "",
+		},
+		{
+			name: "single",
+			err:  db,
+			want: "db.Create",
+		},
+		{
+			name: "multi-level",
+			err:  New(Unknown, WithOp("api.CreateRole"), WithWrap(New(Unknown, WithOp("iam.CreateRole"), WithWrap(db)))),
+			want: "api.CreateRole: iam.CreateRole: db.Create",
+		},
+		{
+			name: "missing-ops",
+			err:  New(Unknown, WithOp("api.CreateRole"), WithWrap(New(Unknown, WithWrap(fmt.Errorf("wrapped: %w", db))))),
+			want: "api.CreateRole: db.Create",
+		},
+		{
+			name: "multiple-wrapped",
+			err:  New(Unknown, WithOp("iam.CreateRoles"), WithWraps(db, New(NotNull, WithOp("db.Update")))),
+			want: "iam.CreateRoles: db.Create: db.Update",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, FullOp(tt.err))
+		})
+	}
+}
+
+func TestChainContains(t *testing.T) {
+	t.Parallel()
+	deep := New(NotNull, WithOp("db.Create"), WithoutStack())
+	for i := 0; i < 10; i++ {
+		deep = New(Unknown, WithWrap(fmt.Errorf("wrapped: %w", deep)), WithoutStack())
+	}
+	siblings := New(InvalidParameter, WithWraps(
+		New(RecordNotFound, WithoutStack()),
+		errors.New("test error"),
+		New(Unknown, WithWraps(errors.New("other error"), New(CheckConstraint, WithoutStack())), WithoutStack()),
+	), WithoutStack())
+	tests := []struct {
+		name string
+		err  error
+		code Code
+		want bool
+	}{
+		{name: "nil", err: nil, code: Unknown, want: false},
+		{name: "std-error", err: errors.New("test error"), code: Unknown, want: false},
+		{name: "outer", err: deep, code: Unknown, want: true},
+		{name: "deep", err: deep, code: NotNull, want: true},
+		{name: "deep-missing", err: deep, code: NotUnique, want: false},
+		{name: "sibling-first", err: siblings, code: RecordNotFound, want: true},
+		{name: "sibling-nested", err: siblings, code: CheckConstraint, want: true},
+		{name: "sibling-missing", err: siblings, code: NotNull, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, ChainContains(tt.err, tt.code))
+		})
+	}
+	t.Run("cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		e := New(InvalidParameter).(*Err)
+		e.Wrapped = e
+		assert.True(ChainContains(e, InvalidParameter))
+		assert.False(ChainContains(e, NotNull))
+	})
+}
@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Code specifies a code for the error.
+type Code uint32
+
+// String returns the Code's symbolic name (for example "NotUnique") or
+// Code(N) when the Code has no name.
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", uint32(c))
+}
+
+// Error satisfies the error interface and returns the Code's String().  A
+// Code is an error so it can be the target of errors.As (see Err.As) and
+// errors.Is (see Err.Is).
+func (c Code) Error() string {
+	return c.String()
+}
+
+// Info returns the Code's Info.  If the Info is not found, it returns the Info
+// for an Unknown Code.
+func (c Code) Info() Info {
+	return codeInfo(c)
+}
+
+// Kind returns the Code's Kind from its Info.
+func (c Code) Kind() Kind {
+	return c.Info().Kind
+}
+
+const (
+	Unknown Code = 0 // Unknown will be equal to a zero value for Codes
+
+	// General function errors are reserved Codes 100-999
+	InvalidParameter Code = 100 // InvalidParameter represents an invalid parameter for an operation.
+	Timeout          Code = 101 // Timeout represents an operation which timed out or exceeded its deadline.
+	Cancelled        Code = 102 // Cancelled represents an operation which was cancelled.
+	MultipleErrors   Code = 103 // MultipleErrors represents multiple errors combined via Combine.
+
+	// DB errors are reserved Codes from 1000-1999
+	CheckConstraint      Code = 1000 // CheckConstraint represents a check constraint error
+	NotNull              Code = 1001 // NotNull represents a value must not be null error
+	NotUnique            Code = 1002 // NotUnique represents a value must be unique error
+	NotSpecificIntegrity Code = 1003 // NotSpecificIntegrity represents an integrity error that has no specific domain error code
+	MissingTable         Code = 1004 // MissingTable represents an undefined table error
+	ForeignKeyViolation  Code = 1005 // ForeignKeyViolation represents a violation of a foreign key constraint
+	TransactionRetryable Code = 1006 // TransactionRetryable represents a transaction that failed (serialization, deadlock) and may succeed if retried
+	ValueTooLong         Code = 1007 // ValueTooLong represents a value which is too long for its column
+	ConnectionFailure    Code = 1008 // ConnectionFailure represents a lost or failed database connection, which may succeed if retried
+	RecordNotFound       Code = 1100 // RecordNotFound represents that a record/row was not found matching the criteria
+	MultipleRecords      Code = 1101 // MultipleRecords represents that multiple records/rows were found matching the criteria when only one was expected
+)
+
+// codeNames provides a map of Codes to their symbolic names.  It must have an
+// entry for every Code constant, and its keys must match the built in Codes
+// of errorCodeInfo and codeSentinels (see TestCodeNames).
+var codeNames = map[Code]string{
+	Unknown:              "Unknown",
+	InvalidParameter:     "InvalidParameter",
+	Timeout:              "Timeout",
+	Cancelled:            "Cancelled",
+	MultipleErrors:       "MultipleErrors",
+	CheckConstraint:      "CheckConstraint",
+	NotNull:              "NotNull",
+	NotUnique:            "NotUnique",
+	NotSpecificIntegrity: "NotSpecificIntegrity",
+	MissingTable:         "MissingTable",
+	ForeignKeyViolation:  "ForeignKeyViolation",
+	TransactionRetryable: "TransactionRetryable",
+	ValueTooLong:         "ValueTooLong",
+	ConnectionFailure:    "ConnectionFailure",
+	RecordNotFound:       "RecordNotFound",
+	MultipleRecords:      "MultipleRecords",
+}
+
+// ValidateCodes returns an error if any declared Code (every Code in
+// codeNames) is missing its errorCodeInfo, or has an Info with an empty
+// Message or an invalid Kind.
+func ValidateCodes() error {
+	codes := make([]Code, 0, len(codeNames))
+	for c := range codeNames {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	var problems []string
+	for _, c := range codes {
+		info, ok := lookupInfo(c)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s (%d) is missing info", c, uint32(c)))
+			continue
+		case info.Message == "":
+			problems = append(problems, fmt.Sprintf("%s (%d) has an empty message", c, uint32(c)))
+		}
+		if !info.Kind.valid() {
+			problems = append(problems, fmt.Sprintf("%s (%d) has an invalid kind %d", c, uint32(c), uint32(info.Kind)))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid codes: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode_String(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		c    Code
+		want string
+	}{
+		{
+			name: "Unknown",
+			c:    Unknown,
+			want: "Unknown",
+		},
+		{
+			name: "NotUnique",
+			c:    NotUnique,
+			want: "NotUnique",
+		},
+		{
+			name: "unregistered-code",
+			c:    Code(99999),
+			want: "Code(99999)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.c.String())
+		})
+	}
+	t.Run("every-code", func(t *testing.T) {
+		for c := range codeNames {
+			assert.NotEmpty(t, c.String())
+			assert.NotContains(t, c.String(), "Code(", "missing name for code %d", uint32(c))
+		}
+	})
+}
+
+// builtinCodeInfo is a copy of errorCodeInfo before any test registers a
+// Code, so it contains only the built in Codes.
+var builtinCodeInfo = func() map[Code]Info {
+	m := make(map[Code]Info, len(errorCodeInfo))
+	for c, info := range errorCodeInfo {
+		m[c] = info
+	}
+	return m
+}()
+
+func TestCodeNames(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	// every built in Code must have a name, an Info and a sentinel, so adding
+	// a Code to one table without the others fails
+	for c := range builtinCodeInfo {
+		assert.Contains(codeNames, c, "code %d has info but no name", uint32(c))
+	}
+	for c, name := range codeNames {
+		assert.Contains(builtinCodeInfo, c, "%s has a name but no info", name)
+		assert.Contains(codeSentinels, c, "%s has a name but no sentinel", name)
+	}
+	assert.Len(codeNames, len(builtinCodeInfo))
+	assert.Len(codeSentinels, len(codeNames))
+}
+
+func TestCode_Kind(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.Equal(Integrity, NotUnique.Kind())
+	assert.Equal(Parameter, InvalidParameter.Kind())
+	assert.Equal(Search, RecordNotFound.Kind())
+	assert.Equal(Other, Code(99999).Kind())
+	assert.Equal(codeInfo(Unknown), Code(99999).Info())
+}
+
+func TestValidateCodes(t *testing.T) {
+	require.NoError(t, ValidateCodes())
+
+	t.Run("invalid", func(t *testing.T) {
+		assert := assert.New(t)
+		const missingInfo, emptyMsg, invalidKind = Code(99997), Code(99998), Code(99999)
+		codeNames[missingInfo] = "missingInfo"
+		codeNames[emptyMsg] = "emptyMsg"
+		codeNames[invalidKind] = "invalidKind"
+		errorCodeInfoLock.Lock()
+		errorCodeInfo[emptyMsg] = Info{Kind: Other}
+		errorCodeInfo[invalidKind] = Info{Message: "invalid kind", Kind: Kind(99999)}
+		errorCodeInfoLock.Unlock()
+		defer func() {
+			for _, c := range []Code{missingInfo, emptyMsg, invalidKind} {
+				delete(codeNames, c)
+				unregisterCode(c)
+			}
+		}()
+		err := ValidateCodes()
+		require.Error(t, err)
+		assert.Equal("invalid codes: missingInfo (99997) is missing info, emptyMsg (99998) has an empty message, invalidKind (99999) has an invalid kind 99999", err.Error())
+	})
+}
@@ -0,0 +1,111 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Equal returns true when the first *Err in the chains of a and b are equal,
+// ignoring volatile fields (such as the stack, timestamp and Caller) so it's
+// stable in tests.  The *Errs are equal when they have the same:
+//
+//	Code
+//	Op
+//	Msg
+//	Codes of every *Err in their chains of wrapped errors, in order (see
+//	Flatten)
+//
+// Nil is only equal to nil, and errors which don't contain an *Err are only
+// equal when they're ==.
+func Equal(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	var ea, eb *Err
+	okA, okB := errors.As(a, &ea), errors.As(b, &eb)
+	switch {
+	case !okA && !okB:
+		return a == b
+	case !okA || !okB:
+		return false
+	case ea == eb:
+		return true
+	}
+	if ea.Code != eb.Code || ea.Op != eb.Op || ea.Msg != eb.Msg {
+		return false
+	}
+	wa, wb := Flatten(ea.Wrapped), Flatten(eb.Wrapped)
+	if len(wa) != len(wb) {
+		return false
+	}
+	for i := range wa {
+		if wa[i].Code != wb[i].Code {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff returns a human-readable description of how got differs from want,
+// with a line per difference in the fields compared by Equal (and the Kind),
+// for example:
+//
+//	code: want NotUnique, got NotNull
+//	msg: want "name is already in use", got "name must not be empty"
+//
+// An empty string is returned when the errors are Equal, so it can be used as:
+//
+//	if diff := errors.Diff(want, got); diff != "" {
+//		t.Errorf("unexpected error:\n%s", diff)
+//	}
+func Diff(want, got error) string {
+	if Equal(want, got) {
+		return ""
+	}
+	var ew, eg *Err
+	okW, okG := want != nil && errors.As(want, &ew), got != nil && errors.As(got, &eg)
+	if !okW || !okG {
+		return fmt.Sprintf("want %s, got %s", describeErr(want, okW), describeErr(got, okG))
+	}
+	var diffs []string
+	if ew.Code != eg.Code {
+		diffs = append(diffs, fmt.Sprintf("code: want %s, got %s", ew.Code, eg.Code))
+	}
+	if kw, kg := ew.Info().Kind, eg.Info().Kind; kw != kg {
+		diffs = append(diffs, fmt.Sprintf("kind: want %s, got %s", kw, kg))
+	}
+	if ew.Op != eg.Op {
+		diffs = append(diffs, fmt.Sprintf("op: want %q, got %q", ew.Op, eg.Op))
+	}
+	if mw, mg := ew.Msg, eg.Msg; mw != mg {
+		diffs = append(diffs, fmt.Sprintf("msg: want %q, got %q", mw, mg))
+	}
+	if cw, cg := chainCodes(ew.Wrapped), chainCodes(eg.Wrapped); cw != cg {
+		diffs = append(diffs, fmt.Sprintf("wrapped: want [%s], got [%s]", cw, cg))
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// describeErr describes err for Diff: its Code when it contains an *Err
+// (isErr), nil or otherwise its quoted Error().
+func describeErr(err error, isErr bool) string {
+	switch {
+	case err == nil:
+		return "nil"
+	case isErr:
+		return fmt.Sprintf("*Err with code %s", GetCode(err))
+	default:
+		return fmt.Sprintf("%q", err.Error())
+	}
+}
+
+// chainCodes returns the Codes of every *Err in the error's chain (see
+// Flatten), separated by spaces.
+func chainCodes(err error) string {
+	var codes []string
+	for _, e := range Flatten(err) {
+		codes = append(codes, e.Code.String())
+	}
+	return strings.Join(codes, " ")
+}
@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("test error")
+	clock := WithClock(func() time.Time { return time.Date(2020, 10, 14, 12, 30, 0, 0, time.UTC) })
+	base := New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull, WithWrap(stdErr))))
+	tests := []struct {
+		name string
+		a, b error
+		want bool
+	}{
+		{
+			name: "nil",
+			want: true,
+		},
+		{
+			name: "one-nil",
+			a:    base,
+			want: false,
+		},
+		{
+			name: "same",
+			a:    base,
+			b:    base,
+			want: true,
+		},
+		{
+			name: "equal",
+			a:    base,
+			b:    New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull, WithOp("eve.Bob"))), WithoutStack(), clock),
+			want: true,
+		},
+		{
+			name: "equal-msgf",
+			a:    base,
+			b:    New(NotUnique, WithOp("alice.Bob"), WithMsgf("test %s", "msg"), WithWrap(New(NotNull))),
+			want: true,
+		},
+		{
+			name: "equal-wrapped",
+			a:    fmt.Errorf("wrapped: %w", base),
+			b:    base,
+			want: true,
+		},
+		{
+			name: "code-different",
+			a:    base,
+			b:    New(NotNull, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull))),
+			want: false,
+		},
+		{
+			name: "op-different",
+			a:    base,
+			b:    New(NotUnique, WithOp("eve.Bob"), WithMsg("test msg"), WithWrap(New(NotNull))),
+			want: false,
+		},
+		{
+			name: "msg-different",
+			a:    base,
+			b:    New(NotUnique, WithOp("alice.Bob"), WithMsg("other msg"), WithWrap(New(NotNull))),
+			want: false,
+		},
+		{
+			name: "wrapped-code-different",
+			a:    base,
+			b:    New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(CheckConstraint))),
+			want: false,
+		},
+		{
+			name: "wrapped-length-different",
+			a:    base,
+			b:    New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg")),
+			want: false,
+		},
+		{
+			name: "std-error",
+			a:    stdErr,
+			b:    stdErr,
+			want: true,
+		},
+		{
+			name: "std-error-different",
+			a:    stdErr,
+			b:    errors.New("test error"),
+			want: false,
+		},
+		{
+			name: "std-error-and-err",
+			a:    stdErr,
+			b:    base,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, Equal(tt.a, tt.b))
+			assert.Equal(tt.want, Equal(tt.b, tt.a))
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("test error")
+	base := New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull, WithWrap(stdErr))))
+	tests := []struct {
+		name      string
+		want, got error
+		wantDiff  string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "equal",
+			want: base,
+			got:  New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull)), WithoutStack()),
+		},
+		{
+			name:     "got-nil",
+			want:     base,
+			wantDiff: "want *Err with code NotUnique, got nil",
+		},
+		{
+			name:     "want-nil",
+			got:      base,
+			wantDiff: "want nil, got *Err with code NotUnique",
+		},
+		{
+			name:     "std-err",
+			want:     base,
+			got:      stdErr,
+			wantDiff: `want *Err with code NotUnique, got "test error"`,
+		},
+		{
+			name:     "code-same-kind",
+			want:     base,
+			got:      New(NotNull, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull))),
+			wantDiff: "code: want NotUnique, got NotNull",
+		},
+		{
+			name:     "code-and-kind",
+			want:     base,
+			got:      New(RecordNotFound, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull))),
+			wantDiff: "code: want NotUnique, got RecordNotFound\nkind: want integrity violation, got search issue",
+		},
+		{
+			name:     "op-and-msg",
+			want:     base,
+			got:      New(NotUnique, WithOp("eve.Bob"), WithMsgf("%s msg", "other"), WithWrap(New(NotNull))),
+			wantDiff: "op: want \"alice.Bob\", got \"eve.Bob\"\nmsg: want \"test msg\", got \"other msg\"",
+		},
+		{
+			name:     "wrapped",
+			want:     base,
+			got:      New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(New(NotNull, WithWrap(New(RecordNotFound))))),
+			wantDiff: "wrapped: want [NotNull], got [NotNull RecordNotFound]",
+		},
+		{
+			name:     "wrapped-missing",
+			want:     base,
+			got:      New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(stdErr)),
+			wantDiff: "wrapped: want [NotNull], got []",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.wantDiff, Diff(tt.want, tt.got))
+			assert.Equal(tt.wantDiff == "", Equal(tt.want, tt.got))
+		})
+	}
+}
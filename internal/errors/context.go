@@ -0,0 +1,56 @@
+package errors
+
+import "context"
+
+type contextKey int
+
+// The context keys of the request metadata used by NewContext.  Values are set
+// with ContextWithRequestID, ContextWithUserID and ContextWithTraceID.
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+	traceIDKey
+)
+
+// ContextWithRequestID returns a copy of ctx carrying the request id, which
+// NewContext uses as the Err's RequestID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// ContextWithUserID returns a copy of ctx carrying the user id, which
+// NewContext adds to the Err's Details as "user_id".
+func ContextWithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// ContextWithTraceID returns a copy of ctx carrying the trace id, which
+// NewContext adds to the Err's Details as "trace_id".
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey, id)
+}
+
+// NewContext creates a new Err like New, stamped with the request metadata
+// carried by ctx: the request id (see ContextWithRequestID) is its RequestID,
+// and the user id (see ContextWithUserID) and trace id (see
+// ContextWithTraceID) are added to its Details as "user_id" and "trace_id".
+// Metadata which isn't in ctx is skipped, and the options are applied after
+// the metadata, so WithRequestID() and WithDetails() take precedence.
+func NewContext(ctx context.Context, c Code, opt ...Option) error {
+	var metaOpts []Option
+	if ctx != nil {
+		if id, ok := ctx.Value(requestIDKey).(string); ok && id != "" {
+			metaOpts = append(metaOpts, WithRequestID(id))
+		}
+		details := map[string]string{}
+		if id, ok := ctx.Value(userIDKey).(string); ok && id != "" {
+			details["user_id"] = id
+		}
+		if id, ok := ctx.Value(traceIDKey).(string); ok && id != "" {
+			details["trace_id"] = id
+		}
+		metaOpts = append(metaOpts, WithDetails(details))
+	}
+	// skip runtime.Callers, callers, newErr and NewContext
+	return newErr(4, c, append(metaOpts, opt...)...)
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContext(t *testing.T) {
+	t.Parallel()
+	ctx := ContextWithRequestID(context.Background(), "r_1234567890")
+	ctx = ContextWithUserID(ctx, "u_1234567890")
+	ctx = ContextWithTraceID(ctx, "4bf92f3577b34da6a3ce929d0e0e4736")
+	tests := []struct {
+		name string
+		ctx  context.Context
+		opt  []Option
+		want error
+	}{
+		{
+			name: "metadata",
+			ctx:  ctx,
+			want: New(NotUnique, WithRequestID("r_1234567890"), WithDetails(map[string]string{"user_id": "u_1234567890", "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"})),
+		},
+		{
+			name: "request-id-only",
+			ctx:  ContextWithRequestID(context.Background(), "r_1234567890"),
+			want: New(NotUnique, WithRequestID("r_1234567890")),
+		},
+		{
+			name: "without-metadata",
+			ctx:  context.Background(),
+			want: New(NotUnique),
+		},
+		{
+			name: "nil-ctx",
+			ctx:  nil,
+			want: New(NotUnique),
+		},
+		{
+			name: "options-take-precedence",
+			ctx:  ctx,
+			opt:  []Option{WithRequestID("r_0987654321"), WithDetails(map[string]string{"user_id": "u_0987654321"}), WithOp("alice.Bob")},
+			want: New(NotUnique, WithOp("alice.Bob"), WithRequestID("r_0987654321"), WithDetails(map[string]string{"user_id": "u_0987654321", "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"})),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := NewContext(tt.ctx, NotUnique, tt.opt...)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+		})
+	}
+	t.Run("stack", func(t *testing.T) {
+		assert := assert.New(t)
+		// the stack starts at the caller of NewContext
+		err := NewContext(ctx, NotUnique).(*Err)
+		f, _ := runtime.CallersFrames(err.stack).Next()
+		assert.Contains(f.Function, "TestNewContext")
+	})
+}
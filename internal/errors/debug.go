@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DebugString returns a verbose representation of the Err for debugging.
+// Unlike Error(), it always includes the Code's symbolic name, its Kind, the
+// Op, Msg, Caller, every one of the Details, the full chain of wrapped
+// errors and the Cause, with each wrapped *Err (and a Cause which is an *Err)
+// rendered recursively with its own debug info.  It's safe to call for an Err
+// with a cyclic chain of wrapped Errs.
+func (e *Err) DebugString() string {
+	if e == nil {
+		return ""
+	}
+	return e.debugString(nil)
+}
+
+// debugString returns the debug representation of the Err.  The path
+// contains the Errs which have already been rendered before this one and is
+// used to detect cycles.
+func (e *Err) debugString(path []*Err) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s (%d): %s", e.Code, uint32(e.Code), e.Info().Kind)
+	if e.Op != "" {
+		fmt.Fprintf(&s, "\n%sop: %s", wrappedIndent, e.Op)
+	}
+	if e.Msg != "" {
+		fmt.Fprintf(&s, "\n%smsg: %s", wrappedIndent, e.logMsg())
+	}
+	if e.Caller != "" {
+		fmt.Fprintf(&s, "\n%scaller: %s", wrappedIndent, e.Caller)
+	}
+	if len(e.Details) > 0 {
+		keys := make([]string, 0, len(e.Details))
+		for k := range e.Details {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(&s, "\n%sdetails:", wrappedIndent)
+		for _, k := range keys {
+			fmt.Fprintf(&s, "\n%s%s%s: %s", wrappedIndent, wrappedIndent, k, e.Details[k])
+		}
+	}
+	if e.Wrapped != nil {
+		fmt.Fprintf(&s, "\n%swrapped:", wrappedIndent)
+		s.WriteString("\n" + indent(indent(debugChainString(e.Wrapped, append(path, e)))))
+	}
+	if !isEmptyErr(e.Cause) {
+		fmt.Fprintf(&s, "\n%scause:", wrappedIndent)
+		s.WriteString("\n" + indent(indent(debugChainString(e.Cause, append(path, e)))))
+	}
+	return s.String()
+}
+
+// debugChainString returns the debug representation of err, which is
+// rendered after each Err in the path, using cycleDetected for a cyclic
+// chain or one deeper than maxChainDepth.
+func debugChainString(err error, path []*Err) string {
+	if len(path) >= maxChainDepth {
+		return cycleDetected
+	}
+	switch w := err.(type) {
+	case *Err:
+		if w == nil {
+			return ""
+		}
+		for _, p := range path {
+			if p == w {
+				return cycleDetected
+			}
+		}
+		return w.debugString(path)
+	case wrappedErrors:
+		var s strings.Builder
+		for _, e := range w {
+			join(&s, "\n", debugChainString(e, path))
+		}
+		return s.String()
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_DebugString(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  *Err
+		want string
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "code-only",
+			err:  New(NotUnique).(*Err),
+			want: "NotUnique (1002): integrity violation",
+		},
+		{
+			name: "nested",
+			err: New(InvalidParameter,
+				WithOp("iam.CreateRole"),
+				WithMsg("unable to create role"),
+				WithDetails(map[string]string{"scope": "o_1234567890", "name": "admin"}),
+				WithWrap(New(NotUnique,
+					WithOp("db.Create"),
+					WithWrap(errors.New("pq: duplicate key")),
+				)),
+			).(*Err),
+			want: `InvalidParameter (100): parameter violation
+  op: iam.CreateRole
+  msg: unable to create role
+  details:
+    name: admin
+    scope: o_1234567890
+  wrapped:
+    NotUnique (1002): integrity violation
+      op: db.Create
+      wrapped:
+        pq: duplicate key`,
+		},
+		{
+			name: "multiple-wrapped",
+			err:  New(Unknown, WithWraps(New(NotNull, WithMsg("name")), ErrCheckConstraint)).(*Err),
+			want: `Unknown (0): unknown
+  wrapped:
+    NotNull (1001): integrity violation
+      msg: name
+    check constraint violated`,
+		},
+		{
+			name: "cause",
+			err: New(Unknown,
+				WithWrap(New(InvalidParameter, WithOp("iam.CreateRole"))),
+				WithCause(New(NotUnique, WithWrap(errors.New("pq: duplicate key")))),
+			).(*Err),
+			want: `Unknown (0): unknown
+  wrapped:
+    InvalidParameter (100): parameter violation
+      op: iam.CreateRole
+  cause:
+    NotUnique (1002): integrity violation
+      wrapped:
+        pq: duplicate key`,
+		},
+		{
+			name: "unregistered-code",
+			err:  New(Code(99999)).(*Err),
+			want: "Code(99999) (99999): unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.err.DebugString())
+		})
+	}
+	t.Run("cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		e := New(InvalidParameter).(*Err)
+		e.Wrapped = e
+		assert.Equal("InvalidParameter (100): parameter violation\n  wrapped:\n    ... (cycle detected)", e.DebugString())
+	})
+}
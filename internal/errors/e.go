@@ -0,0 +1,36 @@
+package errors
+
+// E creates a new Err from its arguments, based on each argument's type:
+//
+//	Op: the Err's Op
+//	Code: the Err's Code
+//	string: the Err's Msg
+//	error: the error the Err wraps
+//	Option: applied as if passed to New
+//
+// When an argument type is repeated, the last one wins.  An argument of any
+// other type results in an Unknown Err describing the bad argument.
+func E(args ...interface{}) error {
+	const op = "errors.E"
+	var c Code
+	opts := make([]Option, 0, len(args))
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case Op:
+			opts = append(opts, WithOp(a))
+		case Code:
+			c = a
+		case string:
+			opts = append(opts, WithMsg(a))
+		case error:
+			opts = append(opts, WithWrap(a))
+		case Option:
+			opts = append(opts, a)
+		default:
+			// skip runtime.Callers, callers, newErr and E
+			return newErr(4, Unknown, WithOp(op), WithMsgf("unknown type %T, value %v in error call", arg, arg))
+		}
+	}
+	// skip runtime.Callers, callers, newErr and E
+	return newErr(4, c, opts...)
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE(t *testing.T) {
+	t.Parallel()
+	wrapped := errors.New("test error")
+	tests := []struct {
+		name string
+		args []interface{}
+		want error
+	}{
+		{
+			name: "no-args",
+			args: nil,
+			want: &Err{Code: Unknown},
+		},
+		{
+			name: "op",
+			args: []interface{}{Op("alice.Bob")},
+			want: &Err{Op: "alice.Bob"},
+		},
+		{
+			name: "code",
+			args: []interface{}{NotUnique},
+			want: &Err{Code: NotUnique},
+		},
+		{
+			name: "msg",
+			args: []interface{}{"test msg"},
+			want: &Err{Msg: "test msg"},
+		},
+		{
+			name: "wrapped",
+			args: []interface{}{wrapped},
+			want: &Err{Wrapped: wrapped},
+		},
+		{
+			name: "option",
+			args: []interface{}{NotUnique, WithDetails(map[string]string{"name": "alice"})},
+			want: &Err{Code: NotUnique, Details: map[string]string{"name": "alice"}},
+		},
+		{
+			name: "all",
+			args: []interface{}{Op("alice.Bob"), InvalidParameter, "test msg", wrapped},
+			want: &Err{Op: "alice.Bob", Code: InvalidParameter, Msg: "test msg", Wrapped: wrapped},
+		},
+		{
+			name: "last-wins",
+			args: []interface{}{Op("alice.Bob"), InvalidParameter, "test msg", wrapped, Op("eve.Bob"), NotUnique, "other msg", ErrNotNull},
+			want: &Err{Op: "eve.Bob", Code: NotUnique, Msg: "other msg", Wrapped: ErrNotNull},
+		},
+		{
+			name: "unknown-type",
+			args: []interface{}{NotUnique, 42},
+			want: &Err{Op: "errors.E", Code: Unknown, Msg: "unknown type int, value 42 in error call"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := E(tt.args...)
+			assert.Equal(tt.want, clearVolatile(err))
+		})
+	}
+	t.Run("stack", func(t *testing.T) {
+		assert := assert.New(t)
+		err := E(NotUnique)
+		assert.Contains(fmt.Sprintf("%+v", err), "errors.TestE")
+		f, _ := runtime.CallersFrames(err.(*Err).stack).Next()
+		assert.Contains(f.Function, "errors.TestE")
+	})
+}
@@ -0,0 +1,886 @@
+// Package errors provides the Err type used throughout Boundary to classify
+// errors by Code and Kind, along with Convert to translate lower-level
+// (database) errors into an Err.
+package errors
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxChainDepth is the maximum number of wrapped errors that are walked
+	// when rendering or inspecting an error's chain.
+	maxChainDepth = 64
+
+	// wrappedIndent is the indentation of each line of an Err's wrapped
+	// errors.
+	wrappedIndent = "  "
+
+	// redacted is rendered in place of a Msg which was provided via
+	// WithRedactedMsg().
+	redacted = "[redacted]"
+
+	// cycleDetected is rendered in place of an error's chain when it's
+	// cyclic or deeper than maxChainDepth.
+	cycleDetected = "... (cycle detected)"
+)
+
+// CaptureCaller, when true, records the file:line where each Err is created as
+// its Caller, which is lighter than its stack.  It's false by default, and
+// should only be set during initialization.
+var CaptureCaller bool
+
+// MaxMsgLen, when greater than 0, is the maximum length in bytes of the Msg of
+// each Err created by New, Convert and the other constructors, which bounds the
+// size of log lines when a message is huge (for example a database error's
+// detail echoing a huge value).  A longer Msg is truncated to MaxMsgLen bytes
+// (at a UTF-8 boundary) followed by an ellipsis, and its original length is
+// added to the Err's Details as "msg_length", unless the Details already have
+// a "msg_length" which was provided by the caller.  It's 0 (unlimited) by
+// default, and should only be set during initialization.
+var MaxMsgLen int
+
+// msgLengthKey is the Details key of the original length of a Msg which was
+// truncated to MaxMsgLen.
+const msgLengthKey = "msg_length"
+
+// Op represents an operation (package.function).
+// For example iam.CreateRole
+type Op string
+
+// Err provides the ability to specify a Msg, Op, Code and Wrapped error.
+// Errs must have a Code and all other fields are optional. We've chosen Err
+// over Error for the identifier to support the easy embedding of Errs.  Errs
+// can be embedded without a conflict between the embedded Err and Err.Error().
+type Err struct {
+	// Code is the error's code, which can be used to get the error's
+	// errorCodeInfo, which contains the error's Kind and Message
+	Code Code
+
+	// Msg for the Err
+	Msg string
+
+	// Op represents the operation raising/propagating an error and is optional
+	Op Op
+
+	// Wrapped is the error which this Err wraps and will be nil if there's no
+	// error to wrap.
+	Wrapped error
+
+	// Cause is the optional root cause of the Err, provided via WithCause(),
+	// which is reported by Error() and MarshalJSON() but isn't part of the
+	// chain walked by Unwrap().
+	Cause error
+
+	// RequestID is the optional id of the request which raised the Err, used
+	// to correlate it with other logs.
+	RequestID string
+
+	// Details provides optional structured context for the Err (entity IDs,
+	// parameter names, etc).
+	Details map[string]string
+
+	// Caller is the file:line where the Err was created, which is only
+	// recorded when CaptureCaller is true.
+	Caller string
+
+	// RetryAfter is the optional duration a client should wait before
+	// retrying the failed request (see GetRetryAfter).
+	RetryAfter time.Duration
+
+	// stack is the call stack where the Err was created and will be empty if
+	// WithoutStack() was used.
+	stack stack
+
+	// withoutEvent is true when WithoutEvent() was used, meaning the Err
+	// shouldn't trigger an error event.
+	withoutEvent bool
+
+	// redactMsg is true when WithRedactedMsg() was used, meaning the Msg may
+	// contain sensitive data and is replaced by redacted everywhere except
+	// UserFacingMessage().
+	redactMsg bool
+
+	// severity is provided via WithSeverity() and overrides the default
+	// Severity for the Err's Kind.
+	severity Severity
+
+	// timestamp is when the Err was created.
+	timestamp time.Time
+
+	// locale is provided via WithLocale() and is the language of the Err's
+	// default message.
+	locale string
+
+	// transient is provided via WithTransient() and overrides whether the Err
+	// is transient by default for its Kind.
+	transient *bool
+
+	// kind is provided via WithKind() and overrides the Kind of the Err's
+	// Code.  It's nil when the Kind isn't overridden, so any Kind (including
+	// Other) can be an override.
+	kind *Kind
+
+	// httpStatus is provided via WithHTTPStatus() and overrides the HTTP
+	// status code derived from the Err's Kind.  It's zero when not overridden.
+	httpStatus int
+
+	// suppressKind and suppressCode are true when WithSuppressKind() and
+	// WithSuppressCodeNumber() were used, meaning the Kind and the code number
+	// are omitted from Error().
+	suppressKind bool
+	suppressCode bool
+}
+
+// New creates a new Err and supports the options of:
+// WithOp() - allows you to specify an optional Op (operation)
+// WithMsg() - allows you to specify an optional error msg, if the default
+// msg for the error Code is not sufficient.
+// WithWrap() - allows you to specify an error to wrap
+// WithWraps() - allows you to specify multiple errors to wrap
+// WithCode() - allows you to override the Code c
+// WithoutStack() - allows you to skip capturing the call stack
+// WithDetails() - allows you to specify structured details
+// WithFields() - allows you to specify structured details as key/value pairs
+// WithoutEvent() - allows you to mark the error as not eventable
+// WithRedactedMsg() - allows you to specify an error msg which is redacted
+// from everything but UserFacingMessage()
+// WithSeverity() - allows you to override the default Severity
+// WithRequestID() - allows you to specify the request id
+// WithClock() - allows you to specify the clock used for the timestamp
+// WithLocale() - allows you to specify the language of the default msg
+// WithTransient() - allows you to override whether the error is transient
+// WithInheritCode() - allows an Unknown Code to inherit the wrapped error's
+// Code
+// WithMsgFromWrapped() - allows the msg to default to the wrapped error's
+// message
+// WithRetryAfter() - allows you to specify how long to wait before a retry
+// WithKind() - allows you to override the Kind of the Code
+// WithHTTPStatus() - allows you to override the HTTP status code
+// WithCause() - allows you to specify the root cause
+// WithSuppressKind() - allows you to omit the Kind from Error()
+// WithSuppressCodeNumber() - allows you to omit the code number from Error()
+func New(c Code, opt ...Option) error {
+	// skip runtime.Callers, callers, newErr and New
+	return newErr(4, c, opt...)
+}
+
+// Newf creates a new Err with the Code c and a Msg formatted from the format
+// and args.  It's equivalent to New(c, WithMsgf(format, args...)).
+func Newf(c Code, format string, args ...interface{}) error {
+	// skip runtime.Callers, callers, newErr and Newf
+	return newErr(4, c, WithMsgf(format, args...))
+}
+
+// FromTemplate creates a new Err from the template tpl, for a canonical error
+// which is raised repeatedly with small variations.  Every field of tpl is
+// copied (its Code, Op, Msg, Wrapped, Cause, RequestID, Details, RetryAfter
+// and the options it was created with) except its stack, timestamp and
+// Caller, which are captured for the new Err.  The options are then applied,
+// so WithMsg(), WithWrap(), WithDetails(), etc override the template's fields.
+// tpl isn't modified and a nil tpl is equivalent to an Unknown Code.
+//
+//	var errNameInUse = errors.New(errors.NotUnique, errors.WithOp("iam.CreateRole")).(*errors.Err)
+//	...
+//	return errors.FromTemplate(errNameInUse, errors.WithMsgf("%s is in use", name), errors.WithWrap(err))
+func FromTemplate(tpl *Err, opt ...Option) error {
+	if tpl == nil {
+		// skip runtime.Callers, callers, newErr and FromTemplate
+		return newErr(4, Unknown, opt...)
+	}
+	tplOpts := []Option{
+		WithOp(tpl.Op),
+		WithWrap(tpl.Wrapped),
+		WithCause(tpl.Cause),
+		WithRequestID(tpl.RequestID),
+		WithDetails(tpl.Details),
+		WithSeverity(tpl.severity),
+		WithLocale(tpl.locale),
+		WithRetryAfter(tpl.RetryAfter),
+		WithHTTPStatus(tpl.httpStatus),
+	}
+	switch {
+	case tpl.redactMsg:
+		tplOpts = append(tplOpts, WithRedactedMsg(tpl.Msg))
+	case tpl.Msg != "":
+		tplOpts = append(tplOpts, WithMsg(tpl.Msg))
+	}
+	if tpl.transient != nil {
+		tplOpts = append(tplOpts, WithTransient(*tpl.transient))
+	}
+	if tpl.kind != nil {
+		tplOpts = append(tplOpts, WithKind(*tpl.kind))
+	}
+	if tpl.withoutEvent {
+		tplOpts = append(tplOpts, WithoutEvent())
+	}
+	if tpl.suppressKind {
+		tplOpts = append(tplOpts, WithSuppressKind())
+	}
+	if tpl.suppressCode {
+		tplOpts = append(tplOpts, WithSuppressCodeNumber())
+	}
+	// skip runtime.Callers, callers, newErr and FromTemplate
+	return newErr(4, tpl.Code, append(tplOpts, opt...)...)
+}
+
+// Wrap creates a new Err with the Code c and Op op which wraps err, and
+// supports the same options as New.  It returns nil when err is nil, so it's
+// safe to use when returning the result of a call:
+//
+//	return errors.Wrap(r.writer.Create(ctx, role), op, errors.Unknown)
+func Wrap(err error, op Op, c Code, opt ...Option) error {
+	if err == nil {
+		return nil
+	}
+	opt = append([]Option{WithOp(op), WithWrap(err)}, opt...)
+	// skip runtime.Callers, callers, newErr and Wrap
+	return newErr(4, c, opt...)
+}
+
+// Combine combines the errors into a single error.  Nil errors are ignored:
+// nil is returned when every error is nil and the error itself is returned
+// when only one isn't nil.  Otherwise a MultipleErrors Err is returned which
+// wraps each of the errors, so errors.Is() and errors.As() match any of them,
+// and whose Error() only includes the number of errors and the first error.
+func Combine(errs ...error) error {
+	err := newWrappedErrors(errs...)
+	w, ok := err.(wrappedErrors)
+	if !ok {
+		// nil or the only non-nil error
+		return err
+	}
+	// skip runtime.Callers, callers, newErr and Combine
+	return newErr(4, MultipleErrors, WithMsg(strconv.Itoa(len(w))+" errors"), WithWrap(w))
+}
+
+// WrapDeferred replaces the error errp points to with an Err which wraps it,
+// using the Op op and the wrapped error's Code (see GetCode), and supports the
+// same options as New.  Nothing is done when errp or the error it points to is
+// nil.  It's intended to be deferred with a named error return, so each
+// return doesn't have to wrap its error:
+//
+//	func (r *Repository) CreateRole(ctx context.Context, role *Role) (_ *Role, retErr error) {
+//		const op = "iam.(Repository).CreateRole"
+//		defer errors.WrapDeferred(&retErr, op)
+//		...
+//	}
+func WrapDeferred(errp *error, op Op, opt ...Option) {
+	if errp == nil || *errp == nil {
+		return
+	}
+	opt = append([]Option{WithOp(op), WithWrap(*errp)}, opt...)
+	// skip runtime.Callers, callers, newErr and WrapDeferred
+	*errp = newErr(4, GetCode(*errp), opt...)
+}
+
+// newErr creates a new Err for New and the other constructors.  The skip is
+// passed to callers when capturing the stack, so it must skip newErr and
+// every constructor frame above it.
+func newErr(skip int, c Code, opt ...Option) *Err {
+	opts := GetOpts(opt...)
+	if opts.withCode != Unknown {
+		c = opts.withCode
+	}
+	if c == Unknown && opts.withInheritCode {
+		c = firstKnownCode(opts.withErrWrapped)
+	}
+	if opts.withMsgFromWrapped && opts.withErrMsg == "" && opts.withErrWrapped != nil {
+		if we := firstErr(opts.withErrWrapped); we != nil {
+			// the wrapped Err's Error() includes its Op, Code, etc
+			opts.withErrMsg = we.UserFacingMessage()
+			opts.withRedactedMsg = we.redactMsg
+		} else {
+			opts.withErrMsg = opts.withErrWrapped.Error()
+		}
+	}
+	err := &Err{
+		Code:    c,
+		Op:      opts.withOp,
+		Wrapped: opts.withErrWrapped,
+		Cause:   opts.withCause,
+		Msg:     opts.withErrMsg,
+
+		RequestID:  opts.withRequestID,
+		RetryAfter: opts.withRetryAfter,
+
+		withoutEvent: opts.withoutEvent,
+		redactMsg:    opts.withRedactedMsg && opts.withErrMsg != "",
+		severity:     opts.withSeverity,
+		locale:       opts.withLocale,
+		transient:    opts.withTransient,
+		kind:         opts.withKind,
+		httpStatus:   opts.withHTTPStatus,
+		suppressKind: opts.withSuppressKind,
+		suppressCode: opts.withSuppressCode,
+	}
+	for k, v := range opts.withDetails {
+		err.Add(k, v)
+	}
+	if MaxMsgLen > 0 {
+		err.truncateMsg(MaxMsgLen)
+	}
+	if !opts.withoutStack {
+		err.stack = callers(skip)
+	}
+	if CaptureCaller {
+		err.Caller = caller(skip)
+	}
+	clock := opts.withClock
+	if clock == nil {
+		clock = time.Now
+	}
+	err.timestamp = clock()
+	observe(err)
+	return err
+}
+
+// Convert will convert the error to a Boundary *Err (returning it as an error)
+// and attempt to add a helpful error msg as well. If that's not possible, it
+// will return the original error.  The options are applied after Convert's
+// own options when creating the *Err, so WithCode() can be used to remap the
+// converted Code and WithOp() to supply an Op.
+//
+// An error is already converted, and is returned unchanged, when any *Err in
+// its chain has a Code other than Unknown, even if it's buried under another
+// error (for example a driver error which wraps it).  An error whose only
+// Errs are Unknown is still converted, and the converted Err wraps it so its
+// Errs' Ops, Msgs, etc are kept.
+func Convert(e error, opt ...Option) error {
+	// skip runtime.Callers, callers, newErr, convert and Convert
+	return convert(5, e, opt)
+}
+
+// convert converts the error for Convert and ConvertStrict.  The skip is passed
+// to newErr, so it must skip convert and its caller.
+func convert(skip int, e error, opt []Option) error {
+	// nothing to convert.
+	if e == nil {
+		return nil
+	}
+
+	if alreadyConverted(e) {
+		return e
+	}
+
+	var netError net.Error
+	switch {
+	case errors.Is(e, context.DeadlineExceeded):
+		return newErr(skip, Timeout, convertOpts(opt, WithWrap(e))...)
+	case errors.As(e, &netError) && netError.Timeout():
+		return newErr(skip, Timeout, convertOpts(opt, WithWrap(e))...)
+	case errors.Is(e, context.Canceled):
+		return newErr(skip, Cancelled, convertOpts(opt, WithWrap(e))...)
+	case errors.Is(e, driver.ErrBadConn), errors.Is(e, sql.ErrConnDone):
+		// the connection was lost (for example during a failover)
+		return newErr(skip, ConnectionFailure, convertOpts(opt, WithWrap(e))...)
+	case errors.Is(e, sql.ErrNoRows):
+		return newErr(skip, RecordNotFound, convertOpts(opt, WithWrap(e))...)
+	}
+
+	if pgErr, ok := asPgError(e); ok {
+		// skip convertPgError too
+		if converted := convertPgError(skip+1, e, pgErr, opt); converted != nil {
+			return converted
+		}
+	}
+	// unfortunately, we can't help.
+	return e
+}
+
+// ConvertStrict converts the error like Convert, but always returns an *Err
+// (as an error) for a non-nil error: when Convert returns an error which isn't
+// an *Err, it's wrapped in an Err which inherits the Code of an Err in its
+// chain, or has an Unknown Code when there isn't one (see WithInheritCode).
+// This allows boundaries which expect an *Err to handle every error
+// uniformly.
+func ConvertStrict(e error, opt ...Option) error {
+	// skip runtime.Callers, callers, newErr, convert and ConvertStrict
+	converted := convert(5, e, opt)
+	if converted == nil {
+		return nil
+	}
+	if _, ok := converted.(*Err); ok {
+		return converted
+	}
+	// skip runtime.Callers, callers, newErr and ConvertStrict
+	return newErr(4, Unknown, convertOpts(opt, WithWrap(converted), WithInheritCode())...)
+}
+
+// alreadyConverted returns true when any *Err in the error's chain has a Code
+// other than Unknown.
+func alreadyConverted(e error) bool {
+	return firstKnownCode(e) != Unknown
+}
+
+// convertOpts returns the options Convert uses when creating an *Err, followed
+// by the caller's options so they take precedence.
+func convertOpts(callerOpts []Option, opt ...Option) []Option {
+	return append(opt, callerOpts...)
+}
+
+// Info about the Err
+func (e *Err) Info() Info {
+	if e == nil {
+		return Unknown.Info()
+	}
+	info := e.Code.Info()
+	if e.kind != nil {
+		info.Kind = *e.kind
+	}
+	return info
+}
+
+// GetCode returns the Code of the first *Err in the error's chain.  Unknown is
+// returned for nil and for errors which don't contain an *Err.  It doesn't
+// allocate, so it's safe to call in hot paths such as retry loops.
+func GetCode(err error) Code {
+	e := firstErr(err)
+	if e == nil {
+		return Unknown
+	}
+	return e.Code
+}
+
+// GetRetryAfter returns the RetryAfter of the first *Err in the error's chain
+// with a non-zero RetryAfter, so a hint isn't lost when the Err is wrapped.
+// Zero is returned for nil and for errors without a RetryAfter.
+func GetRetryAfter(err error) time.Duration {
+	var d time.Duration
+	walkChain(err, func(e error) bool {
+		if be, ok := e.(*Err); ok && be != nil {
+			d = be.RetryAfter
+		}
+		return d == 0
+	})
+	return d
+}
+
+// GetKind returns the Kind of the first *Err in the error's chain.  The Kind
+// for an Unknown Code (Other) is returned for nil and for errors which don't
+// contain an *Err.  It doesn't allocate, so it's safe to call in hot paths.
+func GetKind(err error) Kind {
+	e := firstErr(err)
+	if e == nil {
+		return Unknown.Kind()
+	}
+	return e.Info().Kind
+}
+
+// Error satisfies the error interface and returns a string representation of
+// the Err.  It's safe to call for an Err with a cyclic chain of wrapped Errs.
+func (e *Err) Error() string {
+	if e == nil {
+		return ""
+	}
+	b := getBuffer()
+	defer putBuffer(b)
+	e.writeChain(b, nil)
+	return b.String()
+}
+
+// writeChain writes the string representation of the Err followed by its
+// wrapped errors to b.  The path contains the Errs which have already been
+// rendered before this one and is used to detect cycles and to indent each
+// line by len(path) wrappedIndents.
+//
+// The Err is rendered as "op: msg: kind: error #N: request id ID", where the
+// op and request id are omitted when empty and the msg defaults to the Code's
+// Info().Message.  The Cause is rendered on the following line as "caused by:
+// cause", and wrapped errors are rendered on the lines after it, both
+// indented by wrappedIndent.  An Err which is directly wrapped by another Err
+// is rendered in a compact form which doesn't repeat its wrapper: the kind is
+// omitted when it's the same as the wrapper's, and both the kind and the code
+// are omitted when the code is the same as the wrapper's.  The kind and the
+// code are also omitted when the Err was created with WithSuppressKind() and
+// WithSuppressCodeNumber() respectively.
+func (e *Err) writeChain(b *bytes.Buffer, path []*Err) {
+	info := e.Info()
+	msg := e.logMsg()
+	if msg == "" {
+		msg = localizedMessage(e.Code, e.locale) // provide a default.
+	}
+	depth := len(path)
+	if e.Op != "" {
+		writeIndented(b, string(e.Op), depth)
+		b.WriteString(": ")
+	}
+	writeIndented(b, msg, depth)
+	var parent *Err
+	if depth > 0 {
+		parent = path[depth-1]
+	}
+	writeKind, writeCodeNumber := !e.suppressKind, !e.suppressCode
+	switch {
+	case parent != nil && parent.Code == e.Code:
+		writeKind, writeCodeNumber = false, false
+	case parent != nil && parent.Info().Kind == info.Kind:
+		writeKind = false
+	}
+	if writeKind {
+		b.WriteString(": ")
+		b.WriteString(info.Kind.String())
+	}
+	if writeCodeNumber {
+		writeCode(b, e.Code)
+	}
+	if e.RequestID != "" {
+		b.WriteString(": request id ")
+		writeIndented(b, e.RequestID, depth)
+	}
+	if !isEmptyErr(e.Cause) {
+		b.WriteByte('\n')
+		writeIndent(b, depth+1)
+		b.WriteString("caused by: ")
+		writeIndented(b, e.Cause.Error(), depth+1)
+	}
+
+	if !isEmptyErr(e.Wrapped) {
+		wrapped := e.Wrapped
+		if w, ok := wrapped.(wrappedErrors); ok && e.Code == MultipleErrors {
+			// only the first of the combined errors, to keep it compact
+			wrapped = w[0]
+		}
+		path = append(path, e)
+		b.WriteByte('\n')
+		writeIndent(b, len(path))
+		writeChain(b, wrapped, path)
+	}
+}
+
+// Add the key/value to the Err's Details and return the Err, so calls can be
+// chained.
+func (e *Err) Add(key, value string) *Err {
+	if e == nil {
+		return nil
+	}
+	if e.Details == nil {
+		e.Details = map[string]string{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Clone returns a copy of the Err which can be modified without modifying the
+// Err, for transformations of a shared Err.  Its fields are copied, including
+// its Details map, stack and the overrides of its options, but the copy is
+// shallow for its Wrapped and Cause errors, which are the same errors as the
+// Err's.  Nil is returned for nil.
+func (e *Err) Clone() *Err {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	if e.Details != nil {
+		clone.Details = make(map[string]string, len(e.Details))
+		for k, v := range e.Details {
+			clone.Details[k] = v
+		}
+	}
+	if e.stack != nil {
+		clone.stack = append(stack(nil), e.stack...)
+	}
+	if e.transient != nil {
+		transient := *e.transient
+		clone.transient = &transient
+	}
+	if e.kind != nil {
+		kind := *e.kind
+		clone.kind = &kind
+	}
+	return &clone
+}
+
+// WithOp returns the Err re-based on the Op op, for an Err which crosses from
+// one layer to another (for example from a repository to a service), without
+// modifying the Err.  When the Err has no Op, it returns a clone of the Err
+// (see Clone) with the Op op.  Otherwise it returns a new Err with the Op op
+// and the same Code (and Kind) which wraps the Err, like Wrap, so the original
+// Op is kept and FullOp shows both (for example "iam.CreateRole: db.Create").
+// The new Err also keeps the Err's Severity and user-facing Msg (see
+// WithMsgFromWrapped), so it's reported to the requester the same way.  Nil
+// is returned for nil.
+func (e *Err) WithOp(op Op) *Err {
+	if e == nil {
+		return nil
+	}
+	if e.Op == "" {
+		clone := e.Clone()
+		clone.Op = op
+		return clone
+	}
+	opts := []Option{WithOp(op), WithWrap(e), WithSeverity(e.severity)}
+	if e.Msg != "" {
+		opts = append(opts, WithMsgFromWrapped())
+	}
+	if e.kind != nil {
+		opts = append(opts, WithKind(*e.kind))
+	}
+	// skip runtime.Callers, callers, newErr and WithOp
+	return newErr(4, e.Code, opts...)
+}
+
+// Timestamp returns when the Err was created.
+func (e *Err) Timestamp() time.Time {
+	if e == nil {
+		return time.Time{}
+	}
+	return e.timestamp
+}
+
+// Eventable returns true unless the Err was created with WithoutEvent(), so
+// event-emitting middleware can filter errors that shouldn't trigger an error
+// event (for example, expected validation failures).
+func (e *Err) Eventable() bool {
+	return e != nil && !e.withoutEvent
+}
+
+// UserFacingMessage returns a message which is safe to return to end users:
+// the Err's Msg or the Code's default Info().Message (localized when the Err
+// was created WithLocale) when there's no Msg.  It
+// never includes the Op, the Code number or any wrapped errors.
+func (e *Err) UserFacingMessage() string {
+	if e == nil {
+		return ""
+	}
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return localizedMessage(e.Code, e.locale)
+}
+
+// truncateMsg truncates the Msg to max bytes followed by an ellipsis, and adds
+// its original length to the Details without overwriting a caller's
+// "msg_length", when it's longer than max (see MaxMsgLen).
+func (e *Err) truncateMsg(max int) {
+	if len(e.Msg) <= max {
+		return
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(e.Msg[n]) {
+		n--
+	}
+	if _, ok := e.Details[msgLengthKey]; !ok {
+		e.Add(msgLengthKey, strconv.Itoa(len(e.Msg)))
+	}
+	e.Msg = e.Msg[:n] + "..."
+}
+
+// logMsg returns the Msg to include in logs and other output which isn't
+// returned to the requester, which is redacted when WithRedactedMsg() was
+// used.
+func (e *Err) logMsg() string {
+	if e.redactMsg {
+		return redacted
+	}
+	return e.Msg
+}
+
+// Unwrap implements the errors.Unwrap interface and allows callers to use the
+// errors.Is() and errors.As() functions effectively for any wrapped errors.
+// An Err can't have both an Unwrap() error and an Unwrap() []error, so when
+// multiple errors are wrapped via WithWraps() the returned error implements
+// Unwrap() []error over each of them.  When nothing is wrapped (see
+// isEmptyErr), the sentinel error of the Err's Code (for example ErrNotUnique)
+// is returned, so every Err wraps its Code's sentinel without it being part of
+// Wrapped or Error().  Nil is returned for a nil Err, and when nothing is
+// wrapped and the Code has no sentinel (see RegisterCode).
+func (e *Err) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	if isEmptyErr(e.Wrapped) {
+		return codeSentinels[e.Code]
+	}
+	return e.Wrapped
+}
+
+// Is satisfies the interface used by errors.Is and returns true when the
+// target is an *Err, a Code's sentinel error or a Code with the same Code,
+// without comparing any other fields.  This allows errors.Is(err,
+// errors.New(NotUnique)), errors.Is(err, ErrNotUnique) and errors.Is(err,
+// NotUnique) to match any NotUnique Err in the err's chain.  It also returns
+// true when the target is the sentinel error of the Err's Kind, so
+// errors.Is(err, ErrKindIntegrity) matches any Integrity Err.  It doesn't
+// allocate, so errors.Is is safe to call in hot paths.
+func (e *Err) Is(target error) bool {
+	switch t := target.(type) {
+	case *sentinel:
+		return e != nil && e.Code == t.code
+	case *kindSentinel:
+		return e != nil && e.Info().Kind == t.kind
+	case Code:
+		return e != nil && e.Code == t
+	}
+	t, ok := target.(*Err)
+	if !ok {
+		return false
+	}
+	if e == nil || t == nil {
+		return e == t
+	}
+	return e.Code == t.Code
+}
+
+// As allows errors.As() to extract the Code of the first *Err in an error's
+// chain without knowing about *Err:
+//
+//	var c errors.Code
+//	if errors.As(err, &c) { ... }
+//
+// Any other target is left to errors.As().
+func (e *Err) As(target interface{}) bool {
+	c, ok := target.(*Code)
+	if !ok || e == nil || c == nil {
+		return false
+	}
+	*c = e.Code
+	return true
+}
+
+// wrappedErrors are the errors wrapped by an Err via WithWraps()
+type wrappedErrors []error
+
+// newWrappedErrors returns nil if none of errs are non-nil, the error if only
+// one of errs is non-nil and otherwise wrappedErrors of the non-nil errs.
+func newWrappedErrors(errs ...error) error {
+	var w wrappedErrors
+	for _, e := range errs {
+		if !isEmptyErr(e) {
+			w = append(w, e)
+		}
+	}
+	switch len(w) {
+	case 0:
+		return nil
+	case 1:
+		return w[0]
+	default:
+		return w
+	}
+}
+
+// isEmptyErr returns true for errors which don't contain anything to wrap: nil
+// and the errors which are non-nil interfaces but are empty, such as a nil *Err
+// (for example an *Err var which was returned as an error) and empty
+// wrappedErrors.
+func isEmptyErr(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return true
+	case *Err:
+		return e == nil
+	case wrappedErrors:
+		return len(e) == 0
+	}
+	return false
+}
+
+// nilIfEmpty returns nil for an empty error (see isEmptyErr) and otherwise the
+// error, so the wrapping options never wrap an empty error.
+func nilIfEmpty(err error) error {
+	if isEmptyErr(err) {
+		return nil
+	}
+	return err
+}
+
+// Error satisfies the error interface and returns each wrapped error on its
+// own line.
+func (w wrappedErrors) Error() string {
+	b := getBuffer()
+	defer putBuffer(b)
+	writeChain(b, w, nil)
+	return b.String()
+}
+
+// writeChain writes the string representation of err, which is rendered
+// after each Err in the path, to b.  Rather than recursing forever, it writes
+// cycleDetected when err is already in the path or the path is longer than
+// maxChainDepth.  The first line is expected to be indented by the caller.
+func writeChain(b *bytes.Buffer, err error, path []*Err) {
+	if len(path) >= maxChainDepth {
+		b.WriteString(cycleDetected)
+		return
+	}
+	switch w := err.(type) {
+	case *Err:
+		if w == nil {
+			return
+		}
+		for _, p := range path {
+			if p == w {
+				b.WriteString(cycleDetected)
+				return
+			}
+		}
+		w.writeChain(b, path)
+	case wrappedErrors:
+		for i, e := range w {
+			if i > 0 {
+				b.WriteByte('\n')
+				writeIndent(b, len(path))
+			}
+			writeChain(b, e, path)
+		}
+	default:
+		writeIndented(b, err.Error(), len(path))
+	}
+}
+
+// Unwrap returns the wrapped errors, which allows errors.Is() and errors.As()
+// to traverse each of them (Go 1.20+).
+func (w wrappedErrors) Unwrap() []error {
+	return w
+}
+
+// writeCode writes the code as ": error #N" to b.
+func writeCode(b *bytes.Buffer, c Code) {
+	var code [10]byte
+	b.WriteString(": error #")
+	b.Write(strconv.AppendUint(code[:0], uint64(c), 10))
+}
+
+// writeIndent writes depth wrappedIndents to b.
+func writeIndent(b *bytes.Buffer, depth int) {
+	for i := 0; i < depth; i++ {
+		b.WriteString(wrappedIndent)
+	}
+}
+
+// writeIndented writes s to b, indenting each line after the first by depth
+// wrappedIndents.
+func writeIndented(b *bytes.Buffer, s string, depth int) {
+	for depth > 0 {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			break
+		}
+		b.WriteString(s[:i+1])
+		writeIndent(b, depth)
+		s = s[i+1:]
+	}
+	b.WriteString(s)
+}
+
+// indent prefixes each line of s with wrappedIndent.
+func indent(s string) string {
+	return wrappedIndent + strings.ReplaceAll(s, "\n", "\n"+wrappedIndent)
+}
+
+func join(str *strings.Builder, delim string, s string) {
+	if str.Len() == 0 {
+		_, _ = str.WriteString(s)
+		return
+	}
+	_, _ = str.WriteString(delim + s)
+}
@@ -0,0 +1,1832 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/errors/errortest"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNetError is a net.Error for testing
+type testNetError struct {
+	timeout bool
+}
+
+func (e *testNetError) Error() string   { return "test net error" }
+func (e *testNetError) Timeout() bool   { return e.timeout }
+func (e *testNetError) Temporary() bool { return false }
+
+// pqWrapperError wraps a pq.Error and another error.
+type pqWrapperError struct {
+	pqErr   *pq.Error
+	wrapped error
+}
+
+func (e *pqWrapperError) Error() string   { return e.pqErr.Error() }
+func (e *pqWrapperError) Unwrap() []error { return []error{e.pqErr, e.wrapped} }
+
+// clearVolatile returns a copy of err with the stacks and timestamps of it
+// and any wrapped *Err cleared, so it can be compared with errors created
+// elsewhere.
+func clearVolatile(err error) error {
+	e, ok := err.(*Err)
+	if !ok || e == nil {
+		return err
+	}
+	cp := *e
+	cp.stack = nil
+	cp.timestamp = time.Time{}
+	cp.Wrapped = clearVolatile(cp.Wrapped)
+	return &cp
+}
+
+func Test_NewError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		code Code
+		opt  []Option
+		want error
+	}{
+		{
+			name: "all-options",
+			code: InvalidParameter,
+			opt: []Option{
+				WithOp("alice.Bob"),
+				WithWrap(ErrNotUnique),
+				WithMsg("test msg"),
+			},
+			want: &Err{
+				Op:      "alice.Bob",
+				Wrapped: ErrNotUnique,
+				Msg:     "test msg",
+				Code:    InvalidParameter,
+			},
+		},
+		{
+			name: "with-code-precedence",
+			code: InvalidParameter,
+			opt:  []Option{WithCode(NotUnique)},
+			want: &Err{
+				Code: NotUnique,
+			},
+		},
+		{
+			name: "with-code-unknown",
+			code: InvalidParameter,
+			opt:  []Option{WithCode(Unknown)},
+			want: &Err{
+				Code: InvalidParameter,
+			},
+		},
+		{
+			name: "no-options",
+			code: InvalidParameter,
+			opt:  nil,
+			want: &Err{
+				Code: InvalidParameter,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := New(tt.code, tt.opt...)
+			require.Error(t, err)
+			assert.NotEmpty(err.(*Err).stack)
+			assert.Equal(tt.want, clearVolatile(err))
+		})
+	}
+}
+
+func TestNewError_InheritCode(t *testing.T) {
+	t.Parallel()
+	inner := New(NotUnique, WithOp("db.Create"), WithoutStack())
+	tests := []struct {
+		name string
+		code Code
+		opt  []Option
+		want Code
+	}{
+		{
+			name: "inherit",
+			code: Unknown,
+			opt:  []Option{WithWrap(inner), WithInheritCode()},
+			want: NotUnique,
+		},
+		{
+			name: "inherit-std-wrapped",
+			code: Unknown,
+			opt:  []Option{WithWrap(fmt.Errorf("unable to create: %w", fmt.Errorf("wrapped: %w", inner))), WithInheritCode()},
+			want: NotUnique,
+		},
+		{
+			name: "inherit-past-unknown",
+			code: Unknown,
+			opt:  []Option{WithWrap(fmt.Errorf("ctx: %w", Wrap(inner, "alice.Bob", Unknown))), WithInheritCode()},
+			want: NotUnique,
+		},
+		{
+			name: "inherit-without-err",
+			code: Unknown,
+			opt:  []Option{WithWrap(errors.New("test error")), WithInheritCode()},
+			want: Unknown,
+		},
+		{
+			name: "inherit-without-wrapped",
+			code: Unknown,
+			opt:  []Option{WithInheritCode()},
+			want: Unknown,
+		},
+		{
+			name: "not-inherited-by-default",
+			code: Unknown,
+			opt:  []Option{WithWrap(inner)},
+			want: Unknown,
+		},
+		{
+			name: "explicit-code",
+			code: InvalidParameter,
+			opt:  []Option{WithWrap(inner), WithInheritCode()},
+			want: InvalidParameter,
+		},
+		{
+			name: "explicit-with-code",
+			code: Unknown,
+			opt:  []Option{WithWrap(inner), WithInheritCode(), WithCode(RecordNotFound)},
+			want: RecordNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, New(tt.code, tt.opt...).(*Err).Code)
+		})
+	}
+	t.Run("wrap", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal(NotUnique, GetCode(Wrap(inner, "alice.Bob", Unknown, WithInheritCode())))
+		assert.Equal(Unknown, GetCode(Wrap(inner, "alice.Bob", Unknown)))
+	})
+}
+
+func TestNewError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("override", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(InvalidParameter, WithKind(Search), WithoutStack())
+		assert.Equal(Search, err.(*Err).Info().Kind)
+		assert.Equal("invalid parameter", err.(*Err).Info().Message)
+		assert.Equal("invalid parameter: search issue: error #100", err.Error())
+		assert.Equal(Search, GetKind(err))
+		assert.Equal(Search, GetKind(fmt.Errorf("wrapped: %w", err)))
+		assert.Equal(http.StatusNotFound, HTTPStatus(err))
+		// the Code's Info is unchanged
+		assert.Equal(Parameter, InvalidParameter.Kind())
+		assert.Equal(Parameter, GetKind(New(InvalidParameter)))
+	})
+	t.Run("other", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithKind(Other), WithoutStack())
+		assert.Equal(Other, GetKind(err))
+		assert.Equal("must be unique violation: unknown: error #1002", err.Error())
+	})
+	t.Run("no-override", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithoutStack())
+		assert.Equal(Integrity, GetKind(err))
+	})
+}
+
+func TestNewError_WrapNil(t *testing.T) {
+	t.Parallel()
+	var nilErr *Err
+	tests := []struct {
+		name string
+		opt  []Option
+	}{
+		{name: "WithWrap", opt: []Option{WithWrap(nil)}},
+		{name: "WithWrap-nil-err", opt: []Option{WithWrap(nilErr)}},
+		{name: "WithWrapf", opt: []Option{WithWrapf(nil, "test %s", "msg")}},
+		{name: "WithWrapf-nil-err", opt: []Option{WithWrapf(nilErr, "test %s", "msg")}},
+		{name: "WithWraps-none", opt: []Option{WithWraps()}},
+		{name: "WithWraps", opt: []Option{WithWraps(nil, nil)}},
+		{name: "WithWraps-nil-err", opt: []Option{WithWraps(nilErr, nil, wrappedErrors{})}},
+		{name: "WithCause", opt: []Option{WithCause(nil)}},
+		{name: "WithCause-nil-err", opt: []Option{WithCause(nilErr)}},
+		{name: "combined", opt: []Option{WithWrap(errors.New("test error")), WithWraps(nilErr), WithCause(nilErr)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := New(NotUnique, append([]Option{WithMsg("test msg")}, tt.opt...)...).(*Err)
+			assert.Nil(err.Wrapped)
+			assert.Nil(err.Cause)
+			// only the Code's sentinel is wrapped
+			assert.Equal(ErrNotUnique, err.Unwrap())
+			assert.Equal(ErrNotUnique, errors.Unwrap(err))
+			assert.Equal("test msg: integrity violation: error #1002", err.Error())
+		})
+	}
+	t.Run("nil-wrapped-fields", func(t *testing.T) {
+		assert := assert.New(t)
+		// Errs which aren't created by New can still have empty errors
+		for _, wrapped := range []error{nilErr, wrappedErrors{}} {
+			err := &Err{Code: NotUnique, Msg: "test msg", Wrapped: wrapped, Cause: nilErr}
+			assert.Equal(ErrNotUnique, err.Unwrap())
+			assert.Equal("test msg: integrity violation: error #1002", err.Error())
+			assert.True(errors.Is(err, ErrNotUnique))
+		}
+		assert.Nil(nilErr.Unwrap())
+	})
+}
+
+// TestMaxMsgLen isn't parallel, since MaxMsgLen applies to every Err created
+// while it's set.
+func TestMaxMsgLen(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		assert := assert.New(t)
+		msg := strings.Repeat("a", 10000)
+		err := New(NotUnique, WithMsg(msg)).(*Err)
+		assert.Equal(msg, err.Msg)
+		assert.Nil(err.Details)
+	})
+	tests := []struct {
+		name        string
+		err         func() error
+		wantMsg     string
+		wantDetails map[string]string
+	}{
+		{
+			name:    "shorter",
+			err:     func() error { return New(NotUnique, WithMsg("alice")) },
+			wantMsg: "alice",
+		},
+		{
+			name:    "at-limit",
+			err:     func() error { return New(NotUnique, WithMsg("alice bob")) },
+			wantMsg: "alice bob",
+		},
+		{
+			name:        "beyond-limit",
+			err:         func() error { return New(NotUnique, WithMsg("alice bob eve")) },
+			wantMsg:     "alice bob...",
+			wantDetails: map[string]string{"msg_length": "13"},
+		},
+		{
+			name:        "msgf",
+			err:         func() error { return Newf(NotUnique, "%s bob eve", "alice") },
+			wantMsg:     "alice bob...",
+			wantDetails: map[string]string{"msg_length": "13"},
+		},
+		{
+			name:        "utf8-boundary",
+			err:         func() error { return New(NotUnique, WithMsg("alice bbö eve")) },
+			wantMsg:     "alice bb...",
+			wantDetails: map[string]string{"msg_length": "14"},
+		},
+		{
+			name: "with-details",
+			err: func() error {
+				return New(NotUnique, WithMsg("alice bob eve"), WithDetails(map[string]string{"name": "alice"}))
+			},
+			wantMsg:     "alice bob...",
+			wantDetails: map[string]string{"name": "alice", "msg_length": "13"},
+		},
+		{
+			name: "existing-msg-length",
+			err: func() error {
+				return New(NotUnique, WithMsg("alice bob eve"), WithDetails(map[string]string{"msg_length": "user"}))
+			},
+			wantMsg:     "alice bob...",
+			wantDetails: map[string]string{"msg_length": "user"},
+		},
+		{
+			name:        "convert",
+			err:         func() error { return Convert(&pq.Error{Code: "23505", Detail: "duplicate key alice"}) },
+			wantMsg:     "duplicate...",
+			wantDetails: map[string]string{"msg_length": "19"},
+		},
+		{
+			name: "default-msg",
+			err:  func() error { return New(NotUnique) },
+		},
+	}
+	MaxMsgLen = 9
+	defer func() { MaxMsgLen = 0 }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := tt.err().(*Err)
+			assert.Equal(tt.wantMsg, err.Msg)
+			assert.Equal(tt.wantDetails, err.Details)
+		})
+	}
+}
+
+func TestNewError_Cause(t *testing.T) {
+	t.Parallel()
+	cause := errors.New("connection refused")
+	wrapped := errors.New("unable to begin transaction")
+	t.Run("cause-and-wrapped", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := New(Unknown, WithOp("iam.CreateRole"), WithCause(cause), WithWrap(wrapped), WithoutStack()).(*Err)
+		assert.Equal(cause, err.Cause)
+		assert.Equal(wrapped, err.Wrapped)
+		assert.Equal("iam.CreateRole: unknown: unknown: error #0\n  caused by: connection refused\n  unable to begin transaction", err.Error())
+
+		// the unwrap chain is driven by Wrapped
+		assert.Equal(wrapped, errors.Unwrap(err))
+		assert.True(errors.Is(err, wrapped))
+		assert.False(errors.Is(err, cause))
+
+		j, jErr := json.Marshal(clearVolatile(err))
+		require.NoError(jErr)
+		assert.JSONEq(`{"code":0,"kind":"unknown","op":"iam.CreateRole","message":"unknown","wrapped":"unable to begin transaction","cause":"connection refused"}`, string(j))
+	})
+	t.Run("cause-only", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := New(TransactionRetryable, WithCause(cause), WithoutStack()).(*Err)
+		assert.Nil(err.Wrapped)
+		assert.Equal(ErrTransactionRetryable, errors.Unwrap(err))
+		assert.Equal("transaction failed and may be retried: transaction issue: error #1006\n  caused by: connection refused", err.Error())
+
+		j, jErr := json.Marshal(clearVolatile(err))
+		require.NoError(jErr)
+		assert.JSONEq(`{"code":1006,"kind":"transaction_issue","message":"transaction failed and may be retried","cause":"connection refused"}`, string(j))
+	})
+	t.Run("wrapped-only", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := New(Unknown, WithWrap(wrapped), WithoutStack()).(*Err)
+		assert.Nil(err.Cause)
+		j, jErr := json.Marshal(clearVolatile(err))
+		require.NoError(jErr)
+		assert.NotContains(string(j), "cause")
+	})
+	t.Run("nested", func(t *testing.T) {
+		assert := assert.New(t)
+		inner := New(ConnectionFailure, WithOp("db.Begin"), WithCause(cause), WithoutStack())
+		err := New(Unknown, WithOp("iam.CreateRole"), WithWrap(inner), WithoutStack())
+		assert.Equal("iam.CreateRole: unknown: unknown: error #0\n  db.Begin: database connection failure: interrupted operation: error #1008\n    caused by: connection refused", err.Error())
+	})
+}
+
+func TestNewError_MsgFromWrapped(t *testing.T) {
+	t.Parallel()
+	wrapped := errors.New("name must be lowercase")
+	tests := []struct {
+		name string
+		opt  []Option
+		want string
+	}{
+		{
+			name: "from-wrapped",
+			opt:  []Option{WithWrap(wrapped), WithMsgFromWrapped()},
+			want: "name must be lowercase",
+		},
+		{
+			name: "from-wrapped-err",
+			opt:  []Option{WithWrap(New(InvalidParameter, WithMsg("missing name"), WithoutStack())), WithMsgFromWrapped()},
+			want: "missing name",
+		},
+		{
+			name: "from-wrapped-err-default-msg",
+			opt:  []Option{WithWrap(fmt.Errorf("ctx: %w", New(NotNull, WithOp("db.Create")))), WithMsgFromWrapped()},
+			want: "must not be empty (null) violation",
+		},
+		{
+			name: "explicit-msg",
+			opt:  []Option{WithWrap(wrapped), WithMsgFromWrapped(), WithMsg("test msg")},
+			want: "test msg",
+		},
+		{
+			name: "explicit-msgf",
+			opt:  []Option{WithMsgf("test %s", "msg"), WithWrap(wrapped), WithMsgFromWrapped()},
+			want: "test msg",
+		},
+		{
+			name: "without-wrapped",
+			opt:  []Option{WithMsgFromWrapped()},
+			want: "",
+		},
+		{
+			name: "not-by-default",
+			opt:  []Option{WithWrap(wrapped)},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := New(InvalidParameter, tt.opt...).(*Err)
+			assert.Equal(tt.want, err.Msg)
+			assert.NotContains(err.UserFacingMessage(), "error #")
+		})
+	}
+	t.Run("redacted", func(t *testing.T) {
+		assert := assert.New(t)
+		wrapped := New(NotUnique, WithRedactedMsg("alice is already in use"))
+		err := New(InvalidParameter, WithWrap(wrapped), WithMsgFromWrapped()).(*Err)
+		assert.Equal("alice is already in use", err.UserFacingMessage())
+		assert.NotContains(err.Error(), "alice")
+	})
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: 0,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+			want: 0,
+		},
+		{
+			name: "without-retry-after",
+			err:  New(TransactionRetryable),
+			want: 0,
+		},
+		{
+			name: "field",
+			err:  &Err{Code: TransactionRetryable, RetryAfter: time.Second},
+			want: time.Second,
+		},
+		{
+			name: "retry-after",
+			err:  New(TransactionRetryable, WithRetryAfter(30*time.Second)),
+			want: 30 * time.Second,
+		},
+		{
+			name: "wrapped",
+			err:  New(Unknown, WithOp("alice.Bob"), WithWrap(fmt.Errorf("wrapped: %w", New(Timeout, WithRetryAfter(time.Minute))))),
+			want: time.Minute,
+		},
+		{
+			name: "outer-wins",
+			err:  New(Unknown, WithRetryAfter(time.Second), WithWrap(New(Timeout, WithRetryAfter(time.Minute)))),
+			want: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, GetRetryAfter(tt.err))
+		})
+	}
+}
+
+func TestFromTemplate(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("test error")
+	tpl := New(NotUnique, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithDetails(map[string]string{"resource": "role"}), WithSeverity(SeverityWarning), WithTransient(true)).(*Err)
+	tplCopy := *tpl
+	tests := []struct {
+		name string
+		tpl  *Err
+		opt  []Option
+		want error
+	}{
+		{
+			name: "template",
+			tpl:  tpl,
+			want: New(NotUnique, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithDetails(map[string]string{"resource": "role"}), WithSeverity(SeverityWarning), WithTransient(true)),
+		},
+		{
+			name: "msg",
+			tpl:  tpl,
+			opt:  []Option{WithMsg("alice is already in use")},
+			want: New(NotUnique, WithOp("iam.CreateRole"), WithMsg("alice is already in use"), WithDetails(map[string]string{"resource": "role"}), WithSeverity(SeverityWarning), WithTransient(true)),
+		},
+		{
+			name: "msg-and-wrapped",
+			tpl:  tpl,
+			opt:  []Option{WithMsgf("%s is already in use", "eve"), WithWrap(stdErr)},
+			want: New(NotUnique, WithOp("iam.CreateRole"), WithMsg("eve is already in use"), WithWrap(stdErr), WithDetails(map[string]string{"resource": "role"}), WithSeverity(SeverityWarning), WithTransient(true)),
+		},
+		{
+			name: "details",
+			tpl:  tpl,
+			opt:  []Option{WithDetails(map[string]string{"name": "alice"})},
+			want: New(NotUnique, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithDetails(map[string]string{"resource": "role", "name": "alice"}), WithSeverity(SeverityWarning), WithTransient(true)),
+		},
+		{
+			name: "code",
+			tpl:  tpl,
+			opt:  []Option{WithCode(InvalidParameter)},
+			want: New(InvalidParameter, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithDetails(map[string]string{"resource": "role"}), WithSeverity(SeverityWarning), WithTransient(true)),
+		},
+		{
+			name: "redacted-msg",
+			tpl:  New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithWrap(stdErr)).(*Err),
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithWrap(stdErr)),
+		},
+		{
+			name: "nil",
+			tpl:  nil,
+			opt:  []Option{WithMsg("test msg")},
+			want: New(Unknown, WithMsg("test msg")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := FromTemplate(tt.tpl, tt.opt...)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+		})
+	}
+	t.Run("template-not-modified", func(t *testing.T) {
+		assert := assert.New(t)
+		err := FromTemplate(tpl, WithMsg("test msg"), WithWrap(stdErr), WithDetails(map[string]string{"name": "alice"})).(*Err)
+		err.Add("id", "u_1234567890")
+		assert.Equal(&tplCopy, tpl)
+		assert.Equal(map[string]string{"resource": "role"}, tpl.Details)
+		assert.NotEqual(tpl.stack, err.stack)
+	})
+}
+
+func TestNewf(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		code   Code
+		format string
+		args   []interface{}
+	}{
+		{
+			name:   "args",
+			code:   NotNull,
+			format: "%s must not be empty (%d)",
+			args:   []interface{}{"name", 1},
+		},
+		{
+			name:   "no-args",
+			code:   InvalidParameter,
+			format: "missing name",
+		},
+		{
+			name:   "empty",
+			code:   RecordNotFound,
+			format: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got := Newf(tt.code, tt.format, tt.args...)
+			want := New(tt.code, WithMsgf(tt.format, tt.args...))
+			assert.Equal(clearVolatile(want), clearVolatile(got))
+			assert.Equal(want.Error(), got.Error())
+			assert.True(Equal(want, got))
+
+			// the first frame is the caller of Newf
+			f, _ := runtime.CallersFrames(got.(*Err).stack).Next()
+			assert.Contains(f.Function, "errors.TestNewf")
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+	t.Run("nil", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Nil(Wrap(nil, "alice.Bob", InvalidParameter))
+		assert.Nil(Wrap(nil, "alice.Bob", InvalidParameter, WithMsg("test msg")))
+		f := func() error { return nil }
+		assert.NoError(Wrap(f(), "alice.Bob", InvalidParameter))
+	})
+	t.Run("wrapped", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		wrapped := errors.New("test error")
+		err := Wrap(wrapped, "alice.Bob", InvalidParameter)
+		require.Error(err)
+		assert.Equal(&Err{Code: InvalidParameter, Op: "alice.Bob", Wrapped: wrapped}, clearVolatile(err))
+		assert.True(errors.Is(err, wrapped))
+
+		// the first frame is the caller of Wrap
+		f, _ := runtime.CallersFrames(err.(*Err).stack).Next()
+		assert.Contains(f.Function, "errors.TestWrap")
+	})
+	t.Run("options", func(t *testing.T) {
+		assert := assert.New(t)
+		err := Wrap(ErrNotUnique, "alice.Bob", InvalidParameter, WithMsg("test msg"), WithOp("eve.Bob"), WithDetails(map[string]string{"name": "alice"}))
+		want := &Err{
+			Code:    InvalidParameter,
+			Op:      "eve.Bob",
+			Msg:     "test msg",
+			Wrapped: ErrNotUnique,
+			Details: map[string]string{"name": "alice"},
+		}
+		assert.Equal(want, clearVolatile(err))
+	})
+}
+
+func TestCombine(t *testing.T) {
+	t.Parallel()
+	notNull := New(NotNull, WithMsg("name must not be empty"), WithoutStack())
+	notUnique := New(NotUnique, WithOp("db.Create"), WithoutStack())
+	stdErr := errors.New("test error")
+	t.Run("all-nil", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.NoError(Combine())
+		assert.NoError(Combine(nil, nil))
+	})
+	t.Run("single", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal(notNull, Combine(nil, notNull, nil))
+		assert.Equal(stdErr, Combine(stdErr))
+	})
+	t.Run("multiple", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := Combine(notNull, nil, notUnique, stdErr)
+		require.Error(err)
+		assert.Equal(&Err{Code: MultipleErrors, Msg: "3 errors", Wrapped: wrappedErrors{notNull, notUnique, stdErr}}, clearVolatile(err))
+		assert.Equal("3 errors: unknown: error #103\n  name must not be empty: integrity violation: error #1001", err.Error())
+		assert.Equal("3 errors", err.(*Err).UserFacingMessage())
+		assert.True(errors.Is(err, ErrMultipleErrors))
+		assert.True(errors.Is(err, ErrNotNull))
+		assert.True(errors.Is(err, ErrNotUnique))
+		assert.True(errors.Is(err, stdErr))
+		assert.Equal([]error{notNull, notUnique, stdErr}, err.(*Err).Unwrap().(interface{ Unwrap() []error }).Unwrap())
+		assert.Contains(err.(*Err).DebugString(), "db.Create")
+	})
+}
+
+func TestWrapDeferred(t *testing.T) {
+	t.Parallel()
+	create := func(retErr error, opt ...Option) (err error) {
+		defer WrapDeferred(&err, "alice.Bob", opt...)
+		return retErr
+	}
+	t.Run("nil", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.NoError(create(nil))
+		assert.NotPanics(func() { WrapDeferred(nil, "alice.Bob") })
+	})
+	t.Run("err", func(t *testing.T) {
+		assert := assert.New(t)
+		wrapped := New(NotUnique, WithOp("eve.Bob"), WithoutStack())
+		err := create(wrapped)
+		assert.Equal(&Err{Code: NotUnique, Op: "alice.Bob", Wrapped: clearVolatile(wrapped)}, clearVolatile(err))
+		assert.True(errors.Is(err, wrapped))
+
+		// the first frame is the function which deferred WrapDeferred
+		f, _ := runtime.CallersFrames(err.(*Err).stack).Next()
+		assert.Contains(f.Function, "errors.TestWrapDeferred")
+	})
+	t.Run("non-err", func(t *testing.T) {
+		assert := assert.New(t)
+		wrapped := errors.New("test error")
+		err := create(wrapped, WithMsg("test msg"))
+		assert.Equal(&Err{Code: Unknown, Op: "alice.Bob", Msg: "test msg", Wrapped: wrapped}, clearVolatile(err))
+	})
+}
+
+func TestError_Info(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  *Err
+		want Info
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: codeInfo(Unknown),
+		},
+		{
+			name: "Unknown",
+			err:  New(Unknown).(*Err),
+			want: codeInfo(Unknown),
+		},
+		{
+			name: "InvalidParameter",
+			err:  New(InvalidParameter).(*Err),
+			want: codeInfo(InvalidParameter),
+		},
+		{
+			name: "unregistered-code",
+			err:  New(Code(99999)).(*Err),
+			want: codeInfo(Unknown),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.err.Info())
+		})
+	}
+}
+
+func TestGetCodeAndKind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		err      error
+		wantCode Code
+		wantKind Kind
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			wantCode: Unknown,
+			wantKind: Other,
+		},
+		{
+			name:     "std-error",
+			err:      errors.New("test error"),
+			wantCode: Unknown,
+			wantKind: Other,
+		},
+		{
+			name:     "err",
+			err:      New(NotUnique),
+			wantCode: NotUnique,
+			wantKind: Integrity,
+		},
+		{
+			name:     "unregistered-code",
+			err:      New(Code(99999)),
+			wantCode: Code(99999),
+			wantKind: Other,
+		},
+		{
+			name:     "deeply-wrapped",
+			err:      fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", fmt.Errorf("level 3: %w", New(RecordNotFound)))),
+			wantCode: RecordNotFound,
+			wantKind: Search,
+		},
+		{
+			name:     "outermost-err",
+			err:      New(InvalidParameter, WithWrap(New(RecordNotFound))),
+			wantCode: InvalidParameter,
+			wantKind: Parameter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.wantCode, GetCode(tt.err))
+			assert.Equal(tt.wantKind, GetKind(tt.err))
+		})
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "msg",
+			err:  New(Unknown, WithMsg("test msg")),
+			want: "test msg: unknown: error #0",
+		},
+		{
+			name: "code",
+			err:  New(CheckConstraint),
+			want: "constraint check failed: integrity violation: error #1000",
+		},
+		{
+			name: "op-msg-and-code",
+			err:  New(CheckConstraint, WithOp("alice.bob"), WithMsg("test msg")),
+			want: "alice.bob: test msg: integrity violation: error #1000",
+		},
+		{
+			name: "op-and-code",
+			err:  New(CheckConstraint, WithOp("alice.bob")),
+			want: "alice.bob: constraint check failed: integrity violation: error #1000",
+		},
+		{
+			name: "wrapped",
+			err:  New(NotUnique, WithWrap(ErrNotUnique)),
+			want: "must be unique violation: integrity violation: error #1002\n  unique constraint violation",
+		},
+		{
+			name: "request-id",
+			err:  New(CheckConstraint, WithOp("alice.bob"), WithRequestID("r_1234567890")),
+			want: "alice.bob: constraint check failed: integrity violation: error #1000: request id r_1234567890",
+		},
+		{
+			name: "unregistered-code",
+			err:  New(Code(99999), WithMsg("test msg")),
+			want: "test msg: unknown: error #99999",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.err.Error())
+		})
+	}
+	t.Run("nil", func(t *testing.T) {
+		var err *Err
+		assert.Equal(t, "", err.Error())
+	})
+}
+
+func TestError_ErrorFormat(t *testing.T) {
+	t.Parallel()
+	// every combination of empty/non-empty Op, Msg and Wrapped
+	tests := []struct {
+		op      Op
+		msg     string
+		wrapped error
+		want    string
+	}{
+		{
+			want: "must be unique violation: integrity violation: error #1002",
+		},
+		{
+			op:   "alice.Bob",
+			want: "alice.Bob: must be unique violation: integrity violation: error #1002",
+		},
+		{
+			msg:  "test msg",
+			want: "test msg: integrity violation: error #1002",
+		},
+		{
+			op:   "alice.Bob",
+			msg:  "test msg",
+			want: "alice.Bob: test msg: integrity violation: error #1002",
+		},
+		{
+			wrapped: ErrNotUnique,
+			want:    "must be unique violation: integrity violation: error #1002\n  unique constraint violation",
+		},
+		{
+			op:      "alice.Bob",
+			wrapped: ErrNotUnique,
+			want:    "alice.Bob: must be unique violation: integrity violation: error #1002\n  unique constraint violation",
+		},
+		{
+			msg:     "test msg",
+			wrapped: ErrNotUnique,
+			want:    "test msg: integrity violation: error #1002\n  unique constraint violation",
+		},
+		{
+			op:      "alice.Bob",
+			msg:     "test msg",
+			wrapped: ErrNotUnique,
+			want:    "alice.Bob: test msg: integrity violation: error #1002\n  unique constraint violation",
+		},
+		{
+			op:      "alice.Bob",
+			wrapped: New(InvalidParameter, WithOp("eve.Bob"), WithWrap(errors.New("line 1\nline 2"))),
+			want:    "alice.Bob: must be unique violation: integrity violation: error #1002\n  eve.Bob: invalid parameter: parameter violation: error #100\n    line 1\n    line 2",
+		},
+	}
+	for _, tt := range tests {
+		name := fmt.Sprintf("op=%t/msg=%t/wrapped=%t", tt.op != "", tt.msg != "", tt.wrapped != nil)
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := New(NotUnique, WithOp(tt.op), WithMsg(tt.msg), WithWrap(tt.wrapped))
+			assert.Equal(tt.want, err.Error())
+		})
+	}
+}
+
+func TestError_ErrorNested(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		inner   *Err
+		outer   Code
+		verbose string
+		want    string
+	}{
+		{
+			name:    "same-code",
+			inner:   New(NotUnique, WithOp("db.Create"), WithMsg("name is already in use")).(*Err),
+			outer:   NotUnique,
+			verbose: "iam.CreateRole: unable to create role: integrity violation: error #1002\n  db.Create: name is already in use: integrity violation: error #1002",
+			want:    "iam.CreateRole: unable to create role: integrity violation: error #1002\n  db.Create: name is already in use",
+		},
+		{
+			name:    "same-kind",
+			inner:   New(ForeignKeyViolation, WithOp("db.Create"), WithMsg("scope not found")).(*Err),
+			outer:   NotUnique,
+			verbose: "iam.CreateRole: unable to create role: integrity violation: error #1002\n  db.Create: scope not found: integrity violation: error #1005",
+			want:    "iam.CreateRole: unable to create role: integrity violation: error #1002\n  db.Create: scope not found: error #1005",
+		},
+		{
+			name:    "different-kind",
+			inner:   New(RecordNotFound, WithOp("db.LookupById")).(*Err),
+			outer:   NotUnique,
+			verbose: "iam.CreateRole: unable to create role: integrity violation: error #1002\n  db.LookupById: record not found: search issue: error #1100",
+			want:    "iam.CreateRole: unable to create role: integrity violation: error #1002\n  db.LookupById: record not found: search issue: error #1100",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			outer := New(tt.outer, WithOp("iam.CreateRole"), WithMsg("unable to create role"), WithWrap(tt.inner)).(*Err)
+			// the verbose form renders each Err on its own
+			verbose := New(tt.outer, WithOp("iam.CreateRole"), WithMsg("unable to create role")).Error() + "\n" + wrappedIndent + tt.inner.Error()
+			assert.Equal(tt.verbose, verbose)
+			assert.Equal(tt.want, outer.Error())
+		})
+	}
+	t.Run("std-wrapped", func(t *testing.T) {
+		assert := assert.New(t)
+		// an Err wrapped by a std error isn't directly wrapped, so it's rendered
+		// by the std error
+		inner := New(NotUnique, WithMsg("name is already in use"))
+		outer := New(NotUnique, WithWrap(fmt.Errorf("wrapped: %w", inner)))
+		assert.Equal("must be unique violation: integrity violation: error #1002\n  wrapped: name is already in use: integrity violation: error #1002", outer.Error())
+	})
+}
+
+func TestError_ErrorSuppress(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opt  []Option
+		want string
+	}{
+		{
+			name: "default",
+			want: "iam.CreateRole: name is already in use: integrity violation: error #1002: request id r_1234567890",
+		},
+		{
+			name: "suppress-kind",
+			opt:  []Option{WithSuppressKind()},
+			want: "iam.CreateRole: name is already in use: error #1002: request id r_1234567890",
+		},
+		{
+			name: "suppress-code-number",
+			opt:  []Option{WithSuppressCodeNumber()},
+			want: "iam.CreateRole: name is already in use: integrity violation: request id r_1234567890",
+		},
+		{
+			name: "suppress-both",
+			opt:  []Option{WithSuppressKind(), WithSuppressCodeNumber()},
+			want: "iam.CreateRole: name is already in use: request id r_1234567890",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			opt := append([]Option{WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithRequestID("r_1234567890")}, tt.opt...)
+			err := New(NotUnique, opt...).(*Err)
+			assert.Equal(tt.want, err.Error())
+			// the other representations are unchanged
+			assert.Equal(NotUnique, err.Code)
+			assert.Contains(err.DebugString(), "integrity violation")
+		})
+	}
+	t.Run("nested", func(t *testing.T) {
+		assert := assert.New(t)
+		// each Err honors its own options
+		inner := New(RecordNotFound, WithOp("db.LookupById"), WithSuppressKind())
+		outer := New(NotUnique, WithOp("iam.CreateRole"), WithSuppressCodeNumber(), WithWrap(inner))
+		assert.Equal("iam.CreateRole: must be unique violation: integrity violation\n  db.LookupById: record not found: error #1100", outer.Error())
+	})
+	t.Run("template", func(t *testing.T) {
+		assert := assert.New(t)
+		tpl := New(NotUnique, WithSuppressKind(), WithSuppressCodeNumber()).(*Err)
+		assert.Equal("must be unique violation", FromTemplate(tpl).Error())
+	})
+}
+
+func TestError_ErrorCycle(t *testing.T) {
+	t.Parallel()
+	t.Run("self", func(t *testing.T) {
+		assert := assert.New(t)
+		e := New(InvalidParameter, WithOp("alice.Bob")).(*Err)
+		e.Wrapped = e
+		assert.Equal("alice.Bob: invalid parameter: parameter violation: error #100\n  ... (cycle detected)", e.Error())
+		assert.False(IsRetryable(e))
+	})
+	t.Run("indirect", func(t *testing.T) {
+		assert := assert.New(t)
+		inner := New(RecordNotFound).(*Err)
+		outer := New(InvalidParameter, WithWrap(inner)).(*Err)
+		inner.Wrapped = outer
+		assert.Equal(
+			"invalid parameter: parameter violation: error #100\n  record not found: search issue: error #1100\n    ... (cycle detected)",
+			outer.Error(),
+		)
+		assert.False(IsRetryable(outer))
+		assert.Equal(InvalidParameter, GetCode(outer))
+	})
+	t.Run("multiple-wrapped", func(t *testing.T) {
+		assert := assert.New(t)
+		e := New(InvalidParameter).(*Err)
+		e.Wrapped = wrappedErrors{ErrNotNull, e}
+		assert.Equal("invalid parameter: parameter violation: error #100\n  not null constraint violated\n  ... (cycle detected)", e.Error())
+	})
+	t.Run("depth", func(t *testing.T) {
+		assert := assert.New(t)
+		var err error = ErrNotNull
+		for i := 0; i < maxChainDepth+1; i++ {
+			err = New(Unknown, WithWrap(err), WithoutStack())
+		}
+		assert.True(strings.HasSuffix(err.Error(), cycleDetected))
+		assert.NotContains(err.Error(), ErrNotNull.Error())
+	})
+}
+
+func BenchmarkError(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "code",
+			err:  New(NotUnique, WithoutStack()),
+		},
+		{
+			name: "op-msg-request-id",
+			err:  New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"), WithRequestID("r_1234567890"), WithoutStack()),
+		},
+		{
+			name: "wrapped",
+			err: New(NotUnique, WithOp("alice.Bob"), WithoutStack(),
+				WithWrap(New(InvalidParameter, WithOp("eve.Bob"), WithWraps(ErrNotNull, errors.New("line 1\nline 2")), WithoutStack())),
+			),
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = bm.err.Error()
+			}
+		})
+	}
+}
+
+// classifyErr is a chain like those classified in retry loops: a std error
+// wrapping an Err, which wraps another Err.
+var classifyErr = fmt.Errorf("unable to create role: %w", New(TransactionRetryable, WithOp("iam.CreateRole"), WithWrap(New(NotUnique, WithOp("db.Create")))))
+
+// TestClassification_Allocs isn't parallel, since AllocsPerRun can't be
+// called from a parallel test.
+func TestClassification_Allocs(t *testing.T) {
+	assert := assert.New(t)
+	assert.Zero(testing.AllocsPerRun(100, func() { _ = GetCode(classifyErr) }), "GetCode")
+	assert.Zero(testing.AllocsPerRun(100, func() { _ = GetKind(classifyErr) }), "GetKind")
+	assert.Zero(testing.AllocsPerRun(100, func() { _ = IsRetryable(classifyErr) }), "IsRetryable")
+	combined := Combine(New(NotNull), fmt.Errorf("wrapped: %w", New(InvalidParameter)), New(NotUnique))
+	assert.Zero(testing.AllocsPerRun(100, func() { _ = IsRetryable(combined) }), "IsRetryable combined")
+	assert.Zero(testing.AllocsPerRun(100, func() { _ = errors.Is(classifyErr, ErrNotUnique) }), "Is")
+	assert.Zero(testing.AllocsPerRun(100, func() { _ = errors.Is(classifyErr, ErrKindIntegrity) }), "Is kind")
+}
+
+func BenchmarkGetCode(b *testing.B) {
+	if allocs := testing.AllocsPerRun(100, func() { _ = GetCode(classifyErr) }); allocs != 0 {
+		b.Fatalf("GetCode allocates %v times per call", allocs)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = GetCode(classifyErr)
+	}
+}
+
+func BenchmarkIsRetryable(b *testing.B) {
+	if allocs := testing.AllocsPerRun(100, func() { _ = IsRetryable(classifyErr) }); allocs != 0 {
+		b.Fatalf("IsRetryable allocates %v times per call", allocs)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = IsRetryable(classifyErr)
+	}
+}
+
+func BenchmarkIs(b *testing.B) {
+	if allocs := testing.AllocsPerRun(100, func() { _ = errors.Is(classifyErr, ErrNotUnique) }); allocs != 0 {
+		b.Fatalf("Is allocates %v times per call", allocs)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = errors.Is(classifyErr, ErrNotUnique)
+	}
+}
+
+func TestError_Add(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	err := New(NotUnique, WithDetails(map[string]string{"name": "alice"})).(*Err)
+	got := err.Add("id", "u_1234567890").Add("name", "bob")
+	assert.Equal(err, got)
+	assert.Equal(map[string]string{"name": "bob", "id": "u_1234567890"}, err.Details)
+
+	err = New(NotUnique).(*Err)
+	assert.Nil(err.Details)
+	err.Add("name", "alice")
+	assert.Equal(map[string]string{"name": "alice"}, err.Details)
+
+	var nilErr *Err
+	assert.Nil(nilErr.Add("name", "alice"))
+}
+
+func TestError_Clone(t *testing.T) {
+	t.Parallel()
+	t.Run("copy", func(t *testing.T) {
+		assert := assert.New(t)
+		wrapped := errors.New("test error")
+		err := New(NotUnique, WithOp("db.Create"), WithMsgf("%s is already in use", "name"), WithWrap(wrapped), WithCause(wrapped), WithRequestID("r_1234567890"), WithDetails(map[string]string{"name": "alice"}), WithTransient(true), WithKind(Search), WithRetryAfter(time.Second)).(*Err)
+		clone := err.Clone()
+		assert.NotSame(err, clone)
+		assert.Equal(err, clone)
+		assert.Equal(err.Error(), clone.Error())
+		// the wrapped errors are shallow copies
+		assert.Same(wrapped, clone.Wrapped)
+		assert.Same(wrapped, clone.Cause)
+	})
+	t.Run("mutate", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithOp("db.Create"), WithDetails(map[string]string{"name": "alice"}), WithTransient(true), WithKind(Search)).(*Err)
+		orig := *err
+		origStack := append(stack(nil), err.stack...)
+
+		clone := err.Clone()
+		clone.Add("name", "bob").Add("id", "r_1234567890")
+		clone.Details["scope"] = "global"
+		clone.Op = "iam.CreateRole"
+		clone.Code = NotNull
+		*clone.transient = false
+		*clone.kind = Other
+		clone.stack[0] = 0
+
+		assert.Equal(orig, *err)
+		assert.Equal(map[string]string{"name": "alice"}, err.Details)
+		assert.True(err.Transient())
+		assert.Equal(Search, GetKind(err))
+		assert.Equal(origStack, err.stack)
+		assert.Equal(map[string]string{"name": "bob", "id": "r_1234567890", "scope": "global"}, clone.Details)
+	})
+	t.Run("without-details", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique).(*Err)
+		clone := err.Clone()
+		clone.Add("name", "alice")
+		assert.Nil(err.Details)
+	})
+	t.Run("nil", func(t *testing.T) {
+		var nilErr *Err
+		assert.Nil(t, nilErr.Clone())
+	})
+}
+
+func TestError_WithOp(t *testing.T) {
+	t.Parallel()
+	t.Run("without-op", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithMsg("name is already in use"), WithDetails(map[string]string{"name": "alice"})).(*Err)
+		orig := *err
+		got := err.WithOp("iam.CreateRole")
+		assert.NotSame(err, got)
+		assert.Equal(Op("iam.CreateRole"), got.Op)
+		assert.Equal(NotUnique, got.Code)
+		assert.Equal("name is already in use", got.Msg)
+		assert.Nil(got.Wrapped)
+		assert.Equal("iam.CreateRole", FullOp(got))
+
+		// the original isn't modified
+		got.Add("id", "r_1234567890")
+		assert.Equal(orig, *err)
+		assert.Equal(map[string]string{"name": "alice"}, err.Details)
+	})
+	t.Run("with-op", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithOp("db.Create"), WithMsg("name is already in use"), WithKind(Search)).(*Err)
+		orig := *err
+		got := err.WithOp("iam.CreateRole")
+		assert.Equal(Op("iam.CreateRole"), got.Op)
+		assert.Equal(NotUnique, got.Code)
+		assert.Equal(Search, GetKind(got))
+		assert.Same(err, got.Wrapped)
+		assert.Equal("iam.CreateRole: db.Create", FullOp(got))
+		assert.Equal(orig, *err)
+
+		// and again, for another layer
+		got = got.WithOp("handlers.CreateRole")
+		assert.Equal("handlers.CreateRole: iam.CreateRole: db.Create", FullOp(got))
+		assert.True(errors.Is(got, ErrNotUnique))
+		assert.Equal(orig, *err)
+	})
+	t.Run("user-facing", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(NotUnique, WithOp("db.Create"), WithMsg("name is already in use"), WithSeverity(SeverityInfo)).(*Err)
+		got := err.WithOp("iam.CreateRole")
+		assert.Equal("name is already in use", got.UserFacingMessage())
+		assert.Equal("name is already in use", ToAPIError(got).Message)
+		assert.Equal("name is already in use", got.GRPCStatus().Message())
+		assert.Equal(SeverityInfo, got.Severity())
+
+		// a redacted msg stays redacted
+		err = New(NotUnique, WithOp("db.Create"), WithRedactedMsg("alice is already in use")).(*Err)
+		got = err.WithOp("iam.CreateRole")
+		assert.Equal("alice is already in use", got.UserFacingMessage())
+		assert.NotContains(got.Error(), "alice")
+
+		// without a msg, the default message is used
+		err = New(NotUnique, WithOp("db.Create")).(*Err)
+		got = err.WithOp("iam.CreateRole")
+		assert.Empty(got.Msg)
+		assert.Equal("must be unique violation", got.UserFacingMessage())
+		assert.Equal(SeverityCritical, got.Severity())
+	})
+	t.Run("nil", func(t *testing.T) {
+		var nilErr *Err
+		assert.Nil(t, nilErr.WithOp("iam.CreateRole"))
+	})
+}
+
+func TestError_Timestamp(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	ts := time.Date(2020, 10, 14, 12, 30, 0, 0, time.UTC)
+	clock := func() time.Time { return ts }
+	assert.Equal(ts, New(InvalidParameter, WithClock(clock)).(*Err).Timestamp())
+	assert.Equal(ts, Convert(&pq.Error{Code: "23505"}, WithClock(clock)).(*Err).Timestamp())
+
+	before := time.Now()
+	got := New(InvalidParameter).(*Err).Timestamp()
+	assert.False(got.Before(before))
+	assert.False(got.After(time.Now()))
+
+	var nilErr *Err
+	assert.True(nilErr.Timestamp().IsZero())
+}
+
+func TestError_Eventable(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.True(New(InvalidParameter).(*Err).Eventable())
+
+	err := New(InvalidParameter, WithoutEvent())
+	assert.False(err.(*Err).Eventable())
+
+	var e *Err
+	assert.True(errors.As(fmt.Errorf("wrapped: %w", err), &e))
+	assert.False(e.Eventable())
+
+	assert.True(errors.As(New(Unknown, WithWrap(err)).(*Err).Wrapped, &e))
+	assert.False(e.Eventable())
+
+	var nilErr *Err
+	assert.False(nilErr.Eventable())
+}
+
+func TestError_RedactedMsg(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	const secret = "Key (email)=(alice@example.com) already exists."
+	err := New(NotUnique, WithOp("alice.Bob"), WithRedactedMsg(secret)).(*Err)
+
+	assert.Equal(secret, err.UserFacingMessage())
+	assert.Equal("alice.Bob: [redacted]: integrity violation: error #1002", err.Error())
+	assert.NotContains(err.DebugString(), secret)
+	assert.Equal([]interface{}{"code", "NotUnique", "kind", "integrity violation", "op", "alice.Bob", "msg", "[redacted]"}, err.Args())
+	j, jErr := json.Marshal(err)
+	require.NoError(jErr)
+	assert.NotContains(string(j), secret)
+	assert.Contains(string(j), `"message":"[redacted]"`)
+
+	converted := Convert(&pq.Error{Code: "23505", Detail: secret})
+	assert.NotContains(converted.Error(), "alice@example.com")
+	assert.NotContains(converted.(*Err).DebugString(), "alice@example.com")
+	assert.Equal(`email "alice@example.com" is already in use`, converted.(*Err).UserFacingMessage())
+
+	// an empty redacted msg still uses the default message
+	err = New(NotUnique, WithRedactedMsg("")).(*Err)
+	assert.Equal("must be unique violation: integrity violation: error #1002", err.Error())
+}
+
+func TestError_UserFacingMessage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  *Err
+		want string
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "default-msg",
+			err:  New(RecordNotFound, WithOp("alice.Bob")).(*Err),
+			want: "record not found",
+		},
+		{
+			name: "msg",
+			err:  New(InvalidParameter, WithOp("alice.Bob"), WithMsg("missing name")).(*Err),
+			want: "missing name",
+		},
+		{
+			name: "wrapped",
+			err:  New(NotUnique, WithOp("alice.Bob"), WithWrap(New(CheckConstraint, WithOp("eve.Bob")))).(*Err),
+			want: "must be unique violation",
+		},
+		{
+			name: "unregistered-code",
+			err:  New(Code(99999)).(*Err),
+			want: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got := tt.err.UserFacingMessage()
+			assert.Equal(tt.want, got)
+			if tt.err != nil {
+				assert.NotContains(got, fmt.Sprintf("error #%d", tt.err.Code))
+				assert.NotContains(got, "alice.Bob")
+				assert.NotContains(got, "eve.Bob")
+			}
+		})
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	t.Parallel()
+	testErr := New(Unknown, WithMsg("test error"))
+
+	tests := []struct {
+		name      string
+		err       error
+		want      error
+		wantIsErr error
+	}{
+		{
+			name:      "ErrInvalidParameter",
+			err:       New(InvalidParameter, WithWrap(ErrNotUnique)),
+			want:      ErrNotUnique,
+			wantIsErr: ErrNotUnique,
+		},
+		{
+			name:      "testErr",
+			err:       testErr,
+			want:      ErrUnknown,
+			wantIsErr: testErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := tt.err.(interface {
+				Unwrap() error
+			}).Unwrap()
+			assert.Equal(tt.want, err)
+			assert.True(errors.Is(tt.err, tt.wantIsErr))
+		})
+	}
+}
+
+func TestError_UnwrapWithWrapf(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	wrapped := errors.New("test error")
+	err := New(InvalidParameter, WithWrapf(wrapped, "unable to %s %q", "create", "alice"))
+	assert.Equal(`unable to create "alice"`, err.(*Err).Msg)
+	assert.Equal(wrapped, err.(*Err).Wrapped)
+	assert.Equal(wrapped, errors.Unwrap(err))
+	assert.True(errors.Is(err, wrapped))
+}
+
+func TestError_UnwrapMultiple(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	err := New(InvalidParameter, WithWraps(ErrNotNull, fmt.Errorf("wrapped: %w", ErrCheckConstraint), New(RecordNotFound)))
+
+	assert.True(errors.Is(err, ErrNotNull))
+	assert.True(errors.Is(err, ErrCheckConstraint))
+	assert.False(errors.Is(err, ErrNotUnique))
+
+	var e *Err
+	assert.True(errors.As(err.(*Err).Unwrap(), &e))
+	assert.Equal(RecordNotFound, e.Code)
+
+	assert.Equal(
+		"invalid parameter: parameter violation: error #100\n  not null constraint violated\n  wrapped: check constraint violated\n  record not found: search issue: error #1100",
+		err.Error(),
+	)
+}
+
+func TestError_As(t *testing.T) {
+	t.Parallel()
+	t.Run("code", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		inner := New(NotUnique, WithOp("db.Create"))
+		err := fmt.Errorf("wrapped: %w", New(InvalidParameter, WithWrap(inner)))
+		var c Code
+		require.True(errors.As(err, &c))
+		assert.Equal(InvalidParameter, c)
+
+		c = Unknown
+		require.True(errors.As(fmt.Errorf("wrapped: %w", inner), &c))
+		assert.Equal(NotUnique, c)
+
+		c = Unknown
+		assert.False(errors.As(errors.New("test error"), &c))
+		assert.Equal(Unknown, c)
+	})
+	t.Run("err", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		inner := New(NotUnique, WithOp("db.Create"))
+		var e *Err
+		require.True(errors.As(fmt.Errorf("wrapped: %w", inner), &e))
+		assert.Equal(inner, e)
+
+		var nilErr *Err
+		assert.False(nilErr.As(&e))
+		assert.False(inner.(*Err).As((*Code)(nil)))
+	})
+	t.Run("is-code", func(t *testing.T) {
+		assert := assert.New(t)
+		err := fmt.Errorf("wrapped: %w", New(InvalidParameter, WithWrap(New(NotUnique))))
+		assert.True(errors.Is(err, InvalidParameter))
+		assert.True(errors.Is(err, NotUnique))
+		assert.False(errors.Is(err, NotNull))
+		assert.Equal("NotUnique", NotUnique.Error())
+		assert.Equal("NotUnique", fmt.Sprintf("%v", NotUnique))
+	})
+}
+
+func TestConvertError_ConnectionLoss(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	for _, sentinel := range []error{driver.ErrBadConn, sql.ErrConnDone} {
+		err := Convert(Wrap(fmt.Errorf("level 2: %w", sentinel), "db.Query", Unknown))
+		assert.Equal(ConnectionFailure, GetCode(err), "%s", sentinel)
+		assert.True(errors.Is(err, sentinel))
+		var e *Err
+		if assert.True(errors.As(err, &e)) {
+			assert.True(e.Transient())
+		}
+	}
+}
+
+func TestConvertError_KeepsErr(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		pqErr *pq.Error
+		want  Code
+	}{
+		{name: "unique", pqErr: errortest.NewPQError("23505", "Key (name)=(alice) already exists.", "", "", ""), want: NotUnique},
+		{name: "not-null", pqErr: errortest.NewPQError("23502", "", "name", "", ""), want: NotNull},
+		{name: "check", pqErr: errortest.NewPQError("23514", "", "", "name_must_be_lowercase", ""), want: CheckConstraint},
+		{name: "foreign-key", pqErr: errortest.NewPQError("23503", "", "", "iam_scope_parent_id_fkey", "iam_scope"), want: ForeignKeyViolation},
+		{name: "exclusion", pqErr: errortest.NewPQError("23P01", "", "", "session_no_overlap", ""), want: NotSpecificIntegrity},
+		{name: "integrity-class", pqErr: errortest.NewPQError("23000", "", "", "", ""), want: NotSpecificIntegrity},
+		{name: "missing-table", pqErr: errortest.NewPQError("42P01", "", "", "", ""), want: MissingTable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			// the Unknown Err the driver error was wrapped in isn't discarded
+			e := Wrap(tt.pqErr, "iam.CreateRole", Unknown, WithMsg("creating role"))
+			err := Convert(e)
+			assert.Equal(tt.want, GetCode(err))
+			assert.Equal("iam.CreateRole", FullOp(err))
+			assert.Contains(err.Error(), "creating role")
+			assert.True(errors.Is(err, e))
+			assert.True(errors.Is(err, tt.want))
+
+			// and without an Err, the sentinel is still wrapped
+			assert.True(errors.Is(Convert(tt.pqErr), tt.want))
+		})
+	}
+}
+
+func TestConvertError_NoRows(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	e := fmt.Errorf("iam.LookupRole: %w", fmt.Errorf("db.LookupById: %w", fmt.Errorf("row.Scan: %w", sql.ErrNoRows)))
+	err := Convert(Wrap(e, "iam.(Repository).LookupRole", Unknown))
+	assert.Equal(RecordNotFound, GetCode(err))
+	assert.Equal(Search, GetKind(err))
+	assert.True(IsNotFound(err))
+	assert.Equal(http.StatusNotFound, HTTPStatus(err))
+	assert.True(errors.Is(err, sql.ErrNoRows))
+	assert.True(errors.Is(err, ErrRecordNotFound))
+
+	// an error which is already converted isn't changed
+	converted := New(MultipleRecords, WithWrap(e))
+	assert.Equal(converted, Convert(converted))
+}
+
+func TestConvertStrict(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("std error")
+	wrappedErr := fmt.Errorf("wrapped: %w", New(RecordNotFound, WithMsg("test msg")))
+	wrappedUnknownErr := fmt.Errorf("ctx: %w", Wrap(New(NotUnique), "alice.Bob", Unknown))
+	tests := []struct {
+		name        string
+		e           error
+		opt         []Option
+		wantConvert error
+		want        error
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:        "not-convertible",
+			e:           stdErr,
+			wantConvert: stdErr,
+			want:        New(Unknown, WithWrap(stdErr)),
+		},
+		{
+			name:        "not-convertible-with-options",
+			e:           stdErr,
+			opt:         []Option{WithCode(InvalidParameter), WithOp("alice.Bob")},
+			wantConvert: stdErr,
+			want:        New(InvalidParameter, WithOp("alice.Bob"), WithWrap(stdErr)),
+		},
+		{
+			name:        "already-converted",
+			e:           New(InvalidParameter, WithMsg("test msg")),
+			wantConvert: New(InvalidParameter, WithMsg("test msg")),
+			want:        New(InvalidParameter, WithMsg("test msg")),
+		},
+		{
+			name:        "wrapped-err",
+			e:           wrappedErr,
+			wantConvert: wrappedErr,
+			want:        New(RecordNotFound, WithWrap(wrappedErr)),
+		},
+		{
+			name:        "wrapped-unknown-err",
+			e:           wrappedUnknownErr,
+			wantConvert: wrappedUnknownErr,
+			want:        New(NotUnique, WithWrap(wrappedUnknownErr)),
+		},
+		{
+			name: "convertible",
+			e: &pq.Error{
+				Code:       "23514",
+				Constraint: "name_must_be_lowercase",
+			},
+			wantConvert: New(CheckConstraint, WithMsg("name_must_be_lowercase constraint failed"), WithWrap(ErrCheckConstraint)),
+			want:        New(CheckConstraint, WithMsg("name_must_be_lowercase constraint failed"), WithWrap(ErrCheckConstraint)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(clearVolatile(tt.wantConvert), clearVolatile(Convert(tt.e, tt.opt...)))
+			got := ConvertStrict(tt.e, tt.opt...)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(got))
+			if tt.e != nil {
+				_, ok := got.(*Err)
+				assert.True(ok)
+				assert.True(errors.Is(got, Convert(tt.e)))
+			}
+		})
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("std error")
+	serializationErr := &pq.Error{
+		Code:    "40001",
+		Message: "could not serialize access",
+	}
+	deadlockErr := &pq.Error{
+		Code:    "40P01",
+		Message: "deadlock detected",
+	}
+	tooLongErr := &pq.Error{
+		Code:    "22001",
+		Column:  "name",
+		Message: "value too long for type character varying(10)",
+	}
+	tooLongNoColumnErr := &pq.Error{
+		Code:    "22001",
+		Message: "value too long for type character varying(10)",
+	}
+	invalidTextErr := &pq.Error{
+		Code:    "22P02",
+		Message: `invalid input syntax for type uuid: "abc"`,
+	}
+	invalidTextColumnErr := &pq.Error{
+		Code:    "22P02",
+		Column:  "public_id",
+		Message: `invalid input syntax for type uuid: "abc"`,
+	}
+	outOfRangeErr := &pq.Error{
+		Code:    "22003",
+		Message: `value "99999999999" is out of range for type integer`,
+	}
+	outOfRangeColumnErr := &pq.Error{
+		Code:    "22003",
+		Column:  "version",
+		Message: `value "99999999999" is out of range for type integer`,
+	}
+	// a driver error which wraps an already converted Err
+	pqWrappingErr := &pqWrapperError{
+		pqErr:   errortest.NewPQError("23505", "Key (name)=(alice) already exists.", "", "", ""),
+		wrapped: New(InvalidParameter, WithOp("alice.Bob"), WithMsg("test msg")),
+	}
+	queryCanceledErr := &pq.Error{
+		Code:    "57014",
+		Message: "canceling statement due to statement timeout",
+	}
+	deadlineErr := fmt.Errorf("unable to dial: %w", context.DeadlineExceeded)
+	cancelledErr := fmt.Errorf("unable to dial: %w", context.Canceled)
+	badConnErr := fmt.Errorf("unable to query: %w", fmt.Errorf("db.Query: %w", driver.ErrBadConn))
+	connDoneErr := fmt.Errorf("unable to commit: %w", fmt.Errorf("tx.Commit: %w", fmt.Errorf("conn: %w", sql.ErrConnDone)))
+	noRowsErr := fmt.Errorf("unable to lookup: %w", sql.ErrNoRows)
+	netTimeoutErr := &net.OpError{Op: "dial", Net: "tcp", Err: &testNetError{timeout: true}}
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: &testNetError{timeout: false}}
+	tests := []struct {
+		name string
+		e    error
+		opt  []Option
+		want error
+	}{
+		{
+			name: "nil",
+			e:    nil,
+			want: nil,
+		},
+		{
+			name: "override-code",
+			e: &pq.Error{
+				Code:   "23505",
+				Detail: "Key (name)=(alice) already exists.",
+			},
+			opt:  []Option{WithCode(InvalidParameter), WithOp("alice.Bob")},
+			want: New(InvalidParameter, WithOp("alice.Bob"), WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "override-code-not-convertible",
+			e:    stdErr,
+			opt:  []Option{WithCode(InvalidParameter)},
+			want: stdErr,
+		},
+		{
+			name: "not-convertible",
+			e:    stdErr,
+			want: stdErr,
+		},
+		{
+			name: "already-converted",
+			e:    New(InvalidParameter, WithMsg("test msg")),
+			want: New(InvalidParameter, WithMsg("test msg")),
+		},
+		{
+			name: "unique",
+			e:    errortest.NewPQError("23505", "Key (name)=(alice) already exists.", "", "", ""),
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "not-null",
+			e:    errortest.NewPQError("23502", "", "name", "", ""),
+			want: New(NotNull, WithMsg("name must not be empty"), WithWrap(ErrNotNull)),
+		},
+		{
+			name: "check",
+			e:    errortest.NewPQError("23514", "", "", "name_must_be_lowercase", ""),
+			want: New(CheckConstraint, WithMsg("name_must_be_lowercase constraint failed"), WithWrap(ErrCheckConstraint)),
+		},
+		{
+			name: "foreign-key",
+			e:    errortest.NewPQError("23503", "", "", "iam_scope_parent_id_fkey", "iam_scope"),
+			want: New(ForeignKeyViolation, WithMsg("iam_scope_parent_id_fkey constraint failed for iam_scope"), WithWrap(ErrForeignKeyViolation)),
+		},
+		{
+			name: "other-integrity",
+			e: &pq.Error{
+				Code:    "23000",
+				Message: "integrity violation",
+			},
+			want: New(NotSpecificIntegrity, WithMsg("integrity violation")),
+		},
+		{
+			name: "value-too-long",
+			e:    tooLongErr,
+			want: New(ValueTooLong, WithMsg("name value is too long"), WithWrap(tooLongErr)),
+		},
+		{
+			name: "value-too-long-without-column",
+			e:    tooLongNoColumnErr,
+			want: New(ValueTooLong, WithMsg("value too long for type character varying(10)"), WithWrap(tooLongNoColumnErr)),
+		},
+		{
+			name: "invalid-text-representation",
+			e:    invalidTextErr,
+			want: New(InvalidParameter, WithMsg(`invalid input syntax for type uuid: "abc"`), WithWrap(invalidTextErr)),
+		},
+		{
+			name: "invalid-text-representation-with-column",
+			e:    invalidTextColumnErr,
+			want: New(InvalidParameter, WithMsg(`public_id value is invalid: invalid input syntax for type uuid: "abc"`), WithWrap(invalidTextColumnErr)),
+		},
+		{
+			name: "numeric-value-out-of-range",
+			e:    outOfRangeErr,
+			want: New(InvalidParameter, WithMsg(`value "99999999999" is out of range for type integer`), WithWrap(outOfRangeErr)),
+		},
+		{
+			name: "numeric-value-out-of-range-with-column",
+			e:    outOfRangeColumnErr,
+			want: New(InvalidParameter, WithMsg(`version value is out of range: value "99999999999" is out of range for type integer`), WithWrap(outOfRangeColumnErr)),
+		},
+		{
+			name: "serialization-failure",
+			e:    serializationErr,
+			want: New(TransactionRetryable, WithMsg("could not serialize access"), WithWrap(serializationErr)),
+		},
+		{
+			name: "deadlock-detected",
+			e:    deadlockErr,
+			want: New(TransactionRetryable, WithMsg("deadlock detected"), WithWrap(deadlockErr)),
+		},
+		{
+			name: "query-canceled",
+			e:    queryCanceledErr,
+			want: New(Timeout, WithMsg("canceling statement due to statement timeout"), WithWrap(queryCanceledErr)),
+		},
+		{
+			name: "deadline-exceeded",
+			e:    deadlineErr,
+			want: New(Timeout, WithWrap(deadlineErr)),
+		},
+		{
+			name: "net-timeout",
+			e:    netTimeoutErr,
+			want: New(Timeout, WithWrap(netTimeoutErr)),
+		},
+		{
+			name: "net-not-timeout",
+			e:    netErr,
+			want: netErr,
+		},
+		{
+			name: "bad-conn",
+			e:    badConnErr,
+			want: New(ConnectionFailure, WithWrap(badConnErr)),
+		},
+		{
+			name: "conn-done",
+			e:    connDoneErr,
+			want: New(ConnectionFailure, WithWrap(connDoneErr)),
+		},
+		{
+			name: "no-rows",
+			e:    noRowsErr,
+			want: New(RecordNotFound, WithWrap(noRowsErr)),
+		},
+		{
+			name: "cancelled",
+			e:    cancelledErr,
+			want: New(Cancelled, WithWrap(cancelledErr)),
+		},
+		{
+			name: "missing-table",
+			e: &pq.Error{
+				Code:    "42P01",
+				Message: `relation "alice" does not exist`,
+			},
+			want: New(MissingTable, WithMsg(`relation "alice" does not exist`)),
+		},
+		{
+			name: "pq-wrapping-converted",
+			e:    pqWrappingErr,
+			want: pqWrappingErr,
+		},
+		{
+			name: "unknown-wrapping-pq",
+			e:    New(Unknown, WithOp("alice.Bob"), WithWrap(tooLongErr)),
+			want: New(ValueTooLong, WithMsg("name value is too long"), WithWrap(New(Unknown, WithOp("alice.Bob"), WithWrap(tooLongErr)))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := Convert(tt.e, tt.opt...)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+		})
+	}
+}
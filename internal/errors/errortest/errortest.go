@@ -0,0 +1,18 @@
+// Package errortest provides helpers for testing code which converts driver
+// errors with errors.Convert.
+package errortest
+
+import "github.com/lib/pq"
+
+// NewPQError returns a *pq.Error with the SQLSTATE sqlstate and the detail,
+// column, constraint and table, like the errors returned by Postgres, so tests
+// can synthesize driver errors consistently.  Empty fields are left unset.
+func NewPQError(sqlstate, detail, column, constraint, table string) *pq.Error {
+	return &pq.Error{
+		Code:       pq.ErrorCode(sqlstate),
+		Detail:     detail,
+		Column:     column,
+		Constraint: constraint,
+		Table:      table,
+	}
+}
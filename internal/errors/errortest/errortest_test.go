@@ -0,0 +1,22 @@
+package errortest
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPQError(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.Equal(&pq.Error{
+		Code:       "23503",
+		Detail:     `Key (scope_id)=(p_1234567890) is not present in table "iam_scope".`,
+		Column:     "scope_id",
+		Constraint: "iam_role_scope_id_fkey",
+		Table:      "iam_role",
+	}, NewPQError("23503", `Key (scope_id)=(p_1234567890) is not present in table "iam_scope".`, "scope_id", "iam_role_scope_id_fkey", "iam_role"))
+	assert.Equal(&pq.Error{Code: "23505"}, NewPQError("23505", "", "", "", ""))
+	assert.Equal("unique_violation", NewPQError("23505", "", "", "", "").Code.Name())
+}
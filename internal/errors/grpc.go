@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCStatus satisfies the interface used by status.FromError and returns a
+// *status.Status with a codes.Code derived from the Err's Code and Kind, and
+// a message which doesn't include the Err's Op or wrapped errors.  The status
+// carries an errdetails.ErrorInfo whose Reason is the Code's name and whose
+// "kind" metadata is the Kind's WireString(), so clients can branch on them.
+func (e *Err) GRPCStatus() *status.Status {
+	s := status.New(grpcCode(e), e.UserFacingMessage())
+	if e == nil {
+		return s
+	}
+	withInfo, err := s.WithDetails(&errdetails.ErrorInfo{
+		Reason: e.Code.String(),
+		Domain: errorInfoDomain,
+		Metadata: map[string]string{
+			"code": strconv.FormatUint(uint64(e.Code), 10),
+			"kind": e.Info().Kind.WireString(),
+		},
+	})
+	if err != nil {
+		return s
+	}
+	return withInfo
+}
+
+// errorInfoDomain is the Domain of the errdetails.ErrorInfo in an Err's
+// GRPCStatus().
+const errorInfoDomain = "boundary"
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor which converts
+// an error returned by the handler to its GRPCStatus() when the error's chain
+// contains an *Err, so every RPC returns consistent codes and user-safe
+// messages.  Other errors are returned unchanged.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		var e *Err
+		if errors.As(err, &e) {
+			return resp, e.GRPCStatus().Err()
+		}
+		return resp, err
+	}
+}
+
+// grpcCode maps the Err to a codes.Code
+func grpcCode(e *Err) codes.Code {
+	if e == nil {
+		return codes.Unknown
+	}
+	switch e.Code {
+	case NotUnique:
+		return codes.AlreadyExists
+	case Timeout:
+		return codes.DeadlineExceeded
+	case Cancelled:
+		return codes.Canceled
+	case ConnectionFailure:
+		return codes.Unavailable
+	}
+	switch e.Info().Kind {
+	case Parameter:
+		return codes.InvalidArgument
+	case Search:
+		return codes.NotFound
+	case Integrity:
+		return codes.FailedPrecondition
+	case Transaction:
+		return codes.Aborted
+	default:
+		return codes.Unknown
+	}
+}
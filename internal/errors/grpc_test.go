@@ -0,0 +1,160 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestError_GRPCStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{
+			name:     "unknown",
+			err:      New(Unknown),
+			wantCode: codes.Unknown,
+			wantMsg:  "unknown",
+		},
+		{
+			name:     "invalid-parameter",
+			err:      New(InvalidParameter, WithOp("alice.Bob"), WithMsg("missing name")),
+			wantCode: codes.InvalidArgument,
+			wantMsg:  "missing name",
+		},
+		{
+			name:     "not-found",
+			err:      New(RecordNotFound, WithOp("alice.Bob")),
+			wantCode: codes.NotFound,
+			wantMsg:  "record not found",
+		},
+		{
+			name:     "not-unique",
+			err:      New(NotUnique, WithWrap(ErrNotUnique)),
+			wantCode: codes.AlreadyExists,
+			wantMsg:  "must be unique violation",
+		},
+		{
+			name:     "integrity",
+			err:      New(CheckConstraint),
+			wantCode: codes.FailedPrecondition,
+			wantMsg:  "constraint check failed",
+		},
+		{
+			name:     "timeout",
+			err:      New(Timeout),
+			wantCode: codes.DeadlineExceeded,
+			wantMsg:  "timeout",
+		},
+		{
+			name:     "cancelled",
+			err:      New(Cancelled),
+			wantCode: codes.Canceled,
+			wantMsg:  "cancelled",
+		},
+		{
+			name:     "transaction",
+			err:      New(TransactionRetryable),
+			wantCode: codes.Aborted,
+			wantMsg:  "transaction failed and may be retried",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			s, ok := status.FromError(tt.err)
+			assert.True(ok)
+			assert.Equal(tt.wantCode, s.Code())
+			assert.Equal(tt.wantMsg, s.Message())
+		})
+	}
+	t.Run("error-info", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		s := New(NotUnique, WithMsg("name already in use")).(*Err).GRPCStatus()
+		details := s.Details()
+		require.Len(details, 1)
+		info, ok := details[0].(*errdetails.ErrorInfo)
+		require.True(ok)
+		assert.Equal("NotUnique", info.Reason)
+		assert.Equal("boundary", info.Domain)
+		assert.Equal(map[string]string{"code": "1002", "kind": "integrity_violation"}, info.Metadata)
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{
+			name:     "err",
+			err:      New(InvalidParameter, WithOp("alice.Bob"), WithMsg("missing name"), WithWrap(errors.New("secret"))),
+			wantCode: codes.InvalidArgument,
+			wantMsg:  "missing name",
+		},
+		{
+			name:     "wrapped-err",
+			err:      fmt.Errorf("wrapped: %w", New(RecordNotFound, WithOp("alice.Bob"))),
+			wantCode: codes.NotFound,
+			wantMsg:  "record not found",
+		},
+		{
+			name:     "status",
+			err:      status.Error(codes.PermissionDenied, "forbidden"),
+			wantCode: codes.PermissionDenied,
+			wantMsg:  "forbidden",
+		},
+	}
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "resp", tt.err
+			}
+			resp, err := interceptor(context.Background(), "req", info, handler)
+			require.Error(err)
+			assert.Equal("resp", resp)
+			_, isErr := err.(*Err)
+			assert.False(isErr)
+			s, ok := status.FromError(err)
+			require.True(ok)
+			assert.Equal(tt.wantCode, s.Code())
+			assert.Equal(tt.wantMsg, s.Message())
+		})
+	}
+	t.Run("std-error", func(t *testing.T) {
+		assert := assert.New(t)
+		want := errors.New("test error")
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, want
+		}
+		_, err := interceptor(context.Background(), "req", info, handler)
+		assert.Equal(want, err)
+	})
+	t.Run("no-error", func(t *testing.T) {
+		assert := assert.New(t)
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "resp", nil
+		}
+		resp, err := interceptor(context.Background(), "req", info, handler)
+		assert.NoError(err)
+		assert.Equal("resp", resp)
+	})
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// HTTPStatus returns the HTTP status code for the error, based on the Kind of
+// the first *Err in the error's chain:
+//
+//	Parameter: 400 (Bad Request)
+//	Search: 404 (Not Found)
+//	Integrity: 409 (Conflict)
+//
+// All other Kinds, nil and errors which don't contain an *Err return 500
+// (Internal Server Error).  A status provided via WithHTTPStatus() takes
+// precedence over the Kind, using the first *Err in the error's chain which
+// has one.
+func HTTPStatus(err error) int {
+	status := 0
+	walkChain(err, func(e error) bool {
+		if be, ok := e.(*Err); ok && be != nil {
+			status = be.httpStatus
+		}
+		return status == 0
+	})
+	if status != 0 {
+		return status
+	}
+	switch GetKind(err) {
+	case Parameter:
+		return http.StatusBadRequest
+	case Search:
+		return http.StatusNotFound
+	case Integrity:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FromHTTPStatus creates a new Err with the Msg msg for an HTTP error status,
+// for example an upstream error received by a proxy, which is the inverse of
+// HTTPStatus.  The Code is chosen by the status:
+//
+//	400 (Bad Request): InvalidParameter
+//	404 (Not Found): RecordNotFound
+//	409 (Conflict): NotUnique
+//
+// All other statuses (including 5xx) are Unknown.  An error status (400-599)
+// is also provided via WithHTTPStatus(), so HTTPStatus returns the original
+// status even when it isn't derived from the Code's Kind (for example 403 or
+// 503).  The msg defaults to the Code's message when empty.
+func FromHTTPStatus(status int, msg string) error {
+	var c Code
+	switch status {
+	case http.StatusBadRequest:
+		c = InvalidParameter
+	case http.StatusNotFound:
+		c = RecordNotFound
+	case http.StatusConflict:
+		c = NotUnique
+	default:
+		c = Unknown
+	}
+	var opts []Option
+	if msg != "" {
+		opts = append(opts, WithMsg(msg))
+	}
+	if status >= http.StatusBadRequest && status <= 599 {
+		opts = append(opts, WithHTTPStatus(status))
+	}
+	// skip runtime.Callers, callers, newErr and FromHTTPStatus
+	return newErr(4, c, opts...)
+}
+
+// Handler returns an http.Handler which calls h and, when it returns an
+// error, writes the error's APIError (see ToAPIError) as a JSON response with
+// the error's HTTPStatus, and a Retry-After header when the APIError has a
+// RetryAfter.  Nothing is written when h returns nil, so h is responsible for
+// writing successful responses.
+func Handler(h func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := h(w, r)
+		if err == nil {
+			return
+		}
+		apiErr := ToAPIError(err)
+		w.Header().Set("Content-Type", "application/json")
+		if apiErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(apiErr.RetryAfter))
+		}
+		w.WriteHeader(apiErr.Status)
+		_ = json.NewEncoder(w).Encode(apiErr)
+	})
+}
@@ -0,0 +1,258 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: http.StatusInternalServerError,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+			want: http.StatusInternalServerError,
+		},
+		{
+			name: "unknown",
+			err:  New(Unknown),
+			want: http.StatusInternalServerError,
+		},
+		{
+			name: "parameter",
+			err:  New(InvalidParameter),
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "search",
+			err:  New(RecordNotFound),
+			want: http.StatusNotFound,
+		},
+		{
+			name: "integrity",
+			err:  New(NotUnique),
+			want: http.StatusConflict,
+		},
+		{
+			name: "transaction",
+			err:  New(TransactionRetryable),
+			want: http.StatusInternalServerError,
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", New(RecordNotFound))),
+			want: http.StatusNotFound,
+		},
+		{
+			name: "override",
+			err:  New(InvalidParameter, WithHTTPStatus(http.StatusForbidden)),
+			want: http.StatusForbidden,
+		},
+		{
+			name: "override-unknown",
+			err:  New(Unknown, WithHTTPStatus(http.StatusServiceUnavailable)),
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "wrapped-override",
+			err:  New(RecordNotFound, WithWrap(fmt.Errorf("wrapped: %w", New(InvalidParameter, WithHTTPStatus(http.StatusForbidden))))),
+			want: http.StatusForbidden,
+		},
+		{
+			name: "outer-override-wins",
+			err:  New(Unknown, WithHTTPStatus(http.StatusTooManyRequests), WithWrap(New(InvalidParameter, WithHTTPStatus(http.StatusForbidden)))),
+			want: http.StatusTooManyRequests,
+		},
+		{
+			name: "invalid-override",
+			err:  New(InvalidParameter, WithHTTPStatus(42)),
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, HTTPStatus(tt.err))
+		})
+	}
+}
+
+func TestFromHTTPStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		status     int
+		msg        string
+		want       error
+		wantStatus int
+	}{
+		{
+			name:       "bad-request",
+			status:     http.StatusBadRequest,
+			msg:        "missing name",
+			want:       New(InvalidParameter, WithMsg("missing name"), WithHTTPStatus(http.StatusBadRequest)),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not-found",
+			status:     http.StatusNotFound,
+			msg:        "role not found",
+			want:       New(RecordNotFound, WithMsg("role not found"), WithHTTPStatus(http.StatusNotFound)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "conflict",
+			status:     http.StatusConflict,
+			msg:        "name is already in use",
+			want:       New(NotUnique, WithMsg("name is already in use"), WithHTTPStatus(http.StatusConflict)),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "forbidden",
+			status:     http.StatusForbidden,
+			msg:        "forbidden",
+			want:       New(Unknown, WithMsg("forbidden"), WithHTTPStatus(http.StatusForbidden)),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "internal-server-error",
+			status:     http.StatusInternalServerError,
+			msg:        "upstream failure",
+			want:       New(Unknown, WithMsg("upstream failure"), WithHTTPStatus(http.StatusInternalServerError)),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "service-unavailable",
+			status:     http.StatusServiceUnavailable,
+			msg:        "upstream unavailable",
+			want:       New(Unknown, WithMsg("upstream unavailable"), WithHTTPStatus(http.StatusServiceUnavailable)),
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "default-msg",
+			status:     http.StatusNotFound,
+			want:       New(RecordNotFound, WithHTTPStatus(http.StatusNotFound)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "not-an-error-status",
+			status:     http.StatusOK,
+			msg:        "ok",
+			want:       New(Unknown, WithMsg("ok")),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "invalid-status",
+			status:     1200,
+			want:       New(Unknown),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := FromHTTPStatus(tt.status, tt.msg)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+			assert.Equal(tt.wantStatus, HTTPStatus(err))
+		})
+	}
+	t.Run("handler", func(t *testing.T) {
+		assert := assert.New(t)
+		h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+			return FromHTTPStatus(1200, "")
+		})
+		rec := httptest.NewRecorder()
+		assert.NotPanics(func() {
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/roles", nil))
+		})
+		assert.Equal(http.StatusInternalServerError, rec.Code)
+	})
+	t.Run("inverse", func(t *testing.T) {
+		assert := assert.New(t)
+		for _, c := range []Code{InvalidParameter, RecordNotFound, NotUnique} {
+			err := FromHTTPStatus(HTTPStatus(New(c)), "test msg")
+			assert.Equal(c, GetCode(err))
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantBody       string
+		wantRetryAfter string
+	}{
+		{
+			name:       "bad-request",
+			err:        New(InvalidParameter, WithOp("alice.Bob"), WithMsg("missing name"), WithWrap(errors.New("secret"))),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status":400,"code":"InvalidParameter","message":"missing name"}`,
+		},
+		{
+			name:       "not-found",
+			err:        fmt.Errorf("wrapped: %w", New(RecordNotFound, WithDetails(map[string]string{"id": "r_1234567890"}))),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"status":404,"code":"RecordNotFound","message":"record not found","details":{"id":"r_1234567890"}}`,
+		},
+		{
+			name:           "retry-after",
+			err:            New(TransactionRetryable, WithRetryAfter(30*time.Second)),
+			wantStatus:     http.StatusInternalServerError,
+			wantBody:       `{"status":500,"code":"TransactionRetryable","message":"transaction failed and may be retried","retry_after":30}`,
+			wantRetryAfter: "30",
+		},
+		{
+			name:       "invalid-override",
+			err:        New(Unknown, WithHTTPStatus(42)),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"status":500,"code":"Unknown","message":"unknown"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/roles", nil))
+			assert.Equal(tt.wantStatus, rec.Code)
+			assert.Equal("application/json", rec.Header().Get("Content-Type"))
+			assert.JSONEq(tt.wantBody, rec.Body.String())
+			assert.Equal(tt.wantRetryAfter, rec.Header().Get("Retry-After"))
+			_, ok := rec.Header()["Retry-After"]
+			assert.Equal(tt.wantRetryAfter != "", ok)
+		})
+	}
+	t.Run("no-error", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusCreated)
+			_, err := w.Write([]byte("created"))
+			return err
+		})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/roles", nil))
+		require.Equal(http.StatusCreated, rec.Code)
+		assert.Equal("created", rec.Body.String())
+	})
+}
@@ -0,0 +1,84 @@
+package errors
+
+// Info contains details of the specific error code
+type Info struct {
+	// Kind specifies the kind of error (unknown, parameter, integrity, etc).
+	Kind Kind
+
+	// Message provides a default message for the error code
+	Message string
+}
+
+// IsKind returns true when the Info's Kind is k.
+func (i Info) IsKind(k Kind) bool {
+	return i.Kind == k
+}
+
+// errorCodeInfo provides a map of unique Codes (IDs) to their
+// corresponding Kind and a default Message.
+var errorCodeInfo = map[Code]Info{
+	Unknown: {
+		Message: "unknown",
+		Kind:    Other,
+	},
+	InvalidParameter: {
+		Message: "invalid parameter",
+		Kind:    Parameter,
+	},
+	Timeout: {
+		Message: "timeout",
+		Kind:    Interrupted,
+	},
+	Cancelled: {
+		Message: "cancelled",
+		Kind:    Interrupted,
+	},
+	MultipleErrors: {
+		Message: "multiple errors",
+		Kind:    Other,
+	},
+	CheckConstraint: {
+		Message: "constraint check failed",
+		Kind:    Integrity,
+	},
+	NotNull: {
+		Message: "must not be empty (null) violation",
+		Kind:    Integrity,
+	},
+	NotUnique: {
+		Message: "must be unique violation",
+		Kind:    Integrity,
+	},
+	NotSpecificIntegrity: {
+		Message: "Integrity violation without specific details",
+		Kind:    Integrity,
+	},
+	MissingTable: {
+		Message: "missing table",
+		Kind:    Integrity,
+	},
+	ForeignKeyViolation: {
+		Message: "foreign key constraint violation",
+		Kind:    Integrity,
+	},
+	TransactionRetryable: {
+		Message: "transaction failed and may be retried",
+		Kind:    Transaction,
+	},
+	ValueTooLong: {
+		Message: "value is too long",
+		Kind:    Parameter,
+	},
+	ConnectionFailure: {
+		Message: "database connection failure",
+		Kind:    Interrupted,
+	},
+	RecordNotFound: {
+		Message: "record not found",
+		Kind:    Search,
+	},
+	MultipleRecords: {
+		Message: "multiple records",
+		Kind:    Search,
+	},
+}
@@ -0,0 +1,140 @@
+package errors
+
+import "errors"
+
+// IsRetryable returns true when the error, or any *Err within the error's
+// chain, has a Code/Kind which indicates a transient condition that may
+// succeed if the operation is retried: a Transaction Kind (for example a
+// deadlock) or a ConnectionFailure Code (for example a connection reset).
+// Other Interrupted errors, such as Cancelled, aren't retryable.  It returns
+// false for nil and for errors which don't contain an *Err.  Every error
+// wrapped by an error which wraps multiple errors (see Combine and WithWraps)
+// is inspected, not just the first.  At most maxChainDepth errors are walked,
+// so a cyclic chain is safe.  It doesn't allocate, so it's safe to call in
+// retry loops.
+func IsRetryable(err error) bool {
+	retryable, _ := isRetryable(err, 0)
+	return retryable
+}
+
+// isRetryable returns true when any *Err in the error's chain is retryable,
+// depth first, and the number of errors walked, which starts from walked.
+// Like findErr, it doesn't allocate.
+func isRetryable(err error, walked int) (bool, int) {
+	for ; err != nil && walked < maxChainDepth; walked++ {
+		switch w := err.(type) {
+		case *Err:
+			if w == nil {
+				return false, walked
+			}
+			if w.Info().Kind == Transaction || w.Code == ConnectionFailure {
+				return true, walked
+			}
+			err = w.Wrapped
+		case interface{ As(interface{}) bool }:
+			// left to errors.As, like firstErr
+			e := firstErr(err)
+			if e == nil {
+				return false, walked
+			}
+			err = e
+		case interface{ Unwrap() []error }:
+			walked++
+			for _, wrapped := range w.Unwrap() {
+				var retryable bool
+				if retryable, walked = isRetryable(wrapped, walked); retryable {
+					return true, walked
+				}
+			}
+			return false, walked
+		case interface{ Unwrap() error }:
+			err = w.Unwrap()
+		default:
+			return false, walked
+		}
+	}
+	return false, walked
+}
+
+// Match the template Err with the err.  Only the non-zero fields of the
+// template are compared: Code if it's not Unknown, Op and Msg if they're not
+// empty.  The err's chain is searched (via errors.As) for the first *Err to
+// compare, and Match returns false if the template is nil or no *Err is
+// found.
+func Match(template *Err, err error) bool {
+	if template == nil || err == nil {
+		return false
+	}
+	var e *Err
+	if !errors.As(err, &e) {
+		return false
+	}
+	if template.Code != Unknown && template.Code != e.Code {
+		return false
+	}
+	if template.Op != "" && template.Op != e.Op {
+		return false
+	}
+	if template.Msg != "" && template.Msg != e.Msg {
+		return false
+	}
+	return true
+}
+
+// IsNotFound returns true when the first *Err in the error's chain has a
+// RecordNotFound Code.  See Err.IsNotFound.
+func IsNotFound(err error) bool {
+	return firstErr(err).IsNotFound()
+}
+
+// IsNotFound returns true when the Err has a RecordNotFound Code.  It's false
+// for other Codes with a Search Kind, such as MultipleRecords.
+func (e *Err) IsNotFound() bool {
+	return e != nil && e.Code == RecordNotFound
+}
+
+// IsUniqueViolation returns true when the first *Err in the error's chain has
+// a NotUnique Code.
+func IsUniqueViolation(err error) bool {
+	return GetCode(err) == NotUnique
+}
+
+// IsInvalidParameter returns true when the first *Err in the error's chain
+// has a Code with a Parameter Kind (for example InvalidParameter or
+// ValueTooLong).  See Err.IsInvalidParameter.
+func IsInvalidParameter(err error) bool {
+	return firstErr(err).IsInvalidParameter()
+}
+
+// IsInvalidParameter returns true when the Err's Code has a Parameter Kind.
+func (e *Err) IsInvalidParameter() bool {
+	return e.Info().IsKind(Parameter)
+}
+
+// IsIntegrity returns true when the first *Err in the error's chain has a
+// Code with an Integrity Kind (for example NotUnique or CheckConstraint).  See
+// Err.IsIntegrity.
+func IsIntegrity(err error) bool {
+	return firstErr(err).IsIntegrity()
+}
+
+// IsIntegrity returns true when the Err's Code has an Integrity Kind.
+func (e *Err) IsIntegrity() bool {
+	return e.Info().IsKind(Integrity)
+}
+
+// IsCheckConstraint returns true when the first *Err in the error's chain has
+// a CheckConstraint Code.
+func IsCheckConstraint(err error) bool {
+	return GetCode(err) == CheckConstraint
+}
+
+// IsCodeOrKind returns true when the first *Err in the error's chain has the
+// Code c or a Code with the Kind k (for example c ForeignKeyViolation or any
+// Parameter error), so a single call replaces a compound check.  The Kind
+// includes an override provided via WithKind().  It returns false for nil and
+// for errors which don't contain an *Err, even when k is Other.
+func IsCodeOrKind(err error, c Code, k Kind) bool {
+	e := firstErr(err)
+	return e != nil && (e.Code == c || e.Info().Kind == k)
+}
@@ -0,0 +1,422 @@
+package errors
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+	retryable := New(TransactionRetryable, WithMsg("deadlock detected"))
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+			want: false,
+		},
+		{
+			name: "not-retryable",
+			err:  New(NotUnique),
+			want: false,
+		},
+		{
+			name: "retryable",
+			err:  retryable,
+			want: true,
+		},
+		{
+			name: "converted-deadlock",
+			err:  Convert(&pq.Error{Code: "40P01"}),
+			want: true,
+		},
+		{
+			name: "converted-serialization-failure",
+			err:  Convert(&pq.Error{Code: "40001"}),
+			want: true,
+		},
+		{
+			name: "converted-bad-conn",
+			err:  Convert(fmt.Errorf("db.Query: %w", driver.ErrBadConn)),
+			want: true,
+		},
+		{
+			name: "converted-connection-failure",
+			err:  Convert(&pq.Error{Code: "08006"}),
+			want: true,
+		},
+		{
+			name: "cancelled",
+			err:  Convert(context.Canceled),
+			want: false,
+		},
+		{
+			name: "wrapped-by-err",
+			err:  New(Unknown, WithWrap(New(InvalidParameter, WithWrap(New(Unknown, WithWrap(retryable)))))),
+			want: true,
+		},
+		{
+			name: "wrapped-by-std-error",
+			err:  fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", New(Unknown, WithWrap(fmt.Errorf("level 3: %w", retryable))))),
+			want: true,
+		},
+		{
+			name: "wrapped-not-retryable",
+			err:  New(Unknown, WithWrap(New(InvalidParameter, WithWrap(errors.New("test error"))))),
+			want: false,
+		},
+		{
+			name: "combined",
+			err:  Combine(New(NotNull), New(TransactionRetryable)),
+			want: true,
+		},
+		{
+			name: "wraps",
+			err:  New(Unknown, WithWraps(errors.New("test error"), New(InvalidParameter, WithWrap(retryable)))),
+			want: true,
+		},
+		{
+			name: "combined-not-retryable",
+			err:  Combine(New(NotNull), fmt.Errorf("wrapped: %w", New(InvalidParameter))),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	err := New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg"))
+	tests := []struct {
+		name     string
+		template *Err
+		err      error
+		want     bool
+	}{
+		{
+			name:     "nil-template",
+			template: nil,
+			err:      err,
+			want:     false,
+		},
+		{
+			name:     "nil-err",
+			template: &Err{Code: NotUnique},
+			err:      nil,
+			want:     false,
+		},
+		{
+			name:     "std-error",
+			template: &Err{Code: NotUnique},
+			err:      errors.New("test error"),
+			want:     false,
+		},
+		{
+			name:     "empty-template",
+			template: &Err{},
+			err:      err,
+			want:     true,
+		},
+		{
+			name:     "match-code",
+			template: &Err{Code: NotUnique},
+			err:      err,
+			want:     true,
+		},
+		{
+			name:     "match-op",
+			template: &Err{Op: "alice.Bob"},
+			err:      err,
+			want:     true,
+		},
+		{
+			name:     "match-msg",
+			template: &Err{Msg: "test msg"},
+			err:      err,
+			want:     true,
+		},
+		{
+			name:     "match-all",
+			template: &Err{Code: NotUnique, Op: "alice.Bob", Msg: "test msg"},
+			err:      err,
+			want:     true,
+		},
+		{
+			name:     "code-mismatch",
+			template: &Err{Code: NotNull, Op: "alice.Bob"},
+			err:      err,
+			want:     false,
+		},
+		{
+			name:     "op-mismatch",
+			template: &Err{Code: NotUnique, Op: "eve.Bob"},
+			err:      err,
+			want:     false,
+		},
+		{
+			name:     "msg-mismatch",
+			template: &Err{Code: NotUnique, Msg: "other msg"},
+			err:      err,
+			want:     false,
+		},
+		{
+			name:     "wrapped",
+			template: &Err{Code: NotUnique, Op: "alice.Bob"},
+			err:      fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", err)),
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, Match(tt.template, tt.err))
+		})
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{
+			name:   "same-code",
+			err:    New(NotUnique, WithOp("alice.Bob"), WithMsg("test msg")),
+			target: New(NotUnique),
+			want:   true,
+		},
+		{
+			name:   "different-code",
+			err:    New(NotUnique),
+			target: New(NotNull),
+			want:   false,
+		},
+		{
+			name:   "std-error-target",
+			err:    New(NotUnique),
+			target: errors.New("must be unique violation"),
+			want:   false,
+		},
+		{
+			name:   "sentinel-target",
+			err:    New(NotUnique),
+			target: ErrNotUnique,
+			want:   true,
+		},
+		{
+			name:   "wrapped-by-std-error",
+			err:    fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", New(NotUnique))),
+			target: New(NotUnique),
+			want:   true,
+		},
+		{
+			name:   "wrapped-by-err",
+			err:    New(InvalidParameter, WithWrap(New(NotUnique))),
+			target: New(NotUnique),
+			want:   true,
+		},
+		{
+			name:   "wrapped-different-code",
+			err:    New(InvalidParameter, WithWrap(New(NotUnique))),
+			target: New(NotNull),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, errors.Is(tt.err, tt.target))
+		})
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	t.Parallel()
+	wrap := func(err error) error {
+		return fmt.Errorf("level 1: %w", fmt.Errorf("level 2: %w", err))
+	}
+	tests := []struct {
+		name                 string
+		err                  error
+		wantNotFound         bool
+		wantUniqueViolation  bool
+		wantInvalidParameter bool
+		wantCheckConstraint  bool
+		wantIntegrity        bool
+	}{
+		{
+			name: "nil",
+			err:  nil,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+		},
+		{
+			name:         "not-found",
+			err:          New(RecordNotFound),
+			wantNotFound: true,
+		},
+		{
+			name:         "wrapped-not-found",
+			err:          wrap(New(RecordNotFound)),
+			wantNotFound: true,
+		},
+		{
+			name: "multiple-records",
+			err:  New(MultipleRecords),
+		},
+		{
+			name:                "unique",
+			err:                 New(NotUnique),
+			wantUniqueViolation: true,
+			wantIntegrity:       true,
+		},
+		{
+			name:                "converted-unique",
+			err:                 wrap(Convert(&pq.Error{Code: "23505"})),
+			wantUniqueViolation: true,
+			wantIntegrity:       true,
+		},
+		{
+			name:                 "invalid-parameter",
+			err:                  New(InvalidParameter),
+			wantInvalidParameter: true,
+		},
+		{
+			name:                 "wrapped-value-too-long",
+			err:                  wrap(New(ValueTooLong)),
+			wantInvalidParameter: true,
+		},
+		{
+			name:                "check-constraint",
+			err:                 wrap(New(CheckConstraint)),
+			wantCheckConstraint: true,
+			wantIntegrity:       true,
+		},
+		{
+			name:          "other-integrity",
+			err:           New(NotNull),
+			wantIntegrity: true,
+		},
+		{
+			name: "transaction",
+			err:  New(TransactionRetryable),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.wantNotFound, IsNotFound(tt.err))
+			assert.Equal(tt.wantUniqueViolation, IsUniqueViolation(tt.err))
+			assert.Equal(tt.wantInvalidParameter, IsInvalidParameter(tt.err))
+			assert.Equal(tt.wantCheckConstraint, IsCheckConstraint(tt.err))
+			assert.Equal(tt.wantIntegrity, IsIntegrity(tt.err))
+
+			// the methods are consistent with the package functions
+			var e *Err
+			errors.As(tt.err, &e)
+			assert.Equal(tt.wantNotFound, e.IsNotFound())
+			assert.Equal(tt.wantInvalidParameter, e.IsInvalidParameter())
+			assert.Equal(tt.wantIntegrity, e.IsIntegrity())
+		})
+	}
+}
+
+func TestIsCodeOrKind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		c    Code
+		k    Kind
+		want bool
+	}{
+		{
+			name: "match-by-code",
+			err:  New(ForeignKeyViolation),
+			c:    ForeignKeyViolation,
+			k:    Parameter,
+			want: true,
+		},
+		{
+			name: "match-by-kind",
+			err:  New(ValueTooLong),
+			c:    ForeignKeyViolation,
+			k:    Parameter,
+			want: true,
+		},
+		{
+			name: "match-both",
+			err:  New(ForeignKeyViolation),
+			c:    ForeignKeyViolation,
+			k:    Integrity,
+			want: true,
+		},
+		{
+			name: "match-by-kind-override",
+			err:  New(InvalidParameter, WithKind(Search)),
+			c:    RecordNotFound,
+			k:    Search,
+			want: true,
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("level 1: %w", New(NotNull)),
+			c:    ForeignKeyViolation,
+			k:    Integrity,
+			want: true,
+		},
+		{
+			name: "no-match",
+			err:  New(RecordNotFound),
+			c:    ForeignKeyViolation,
+			k:    Parameter,
+		},
+		{
+			name: "first-err-only",
+			err:  New(RecordNotFound, WithWrap(New(ForeignKeyViolation))),
+			c:    ForeignKeyViolation,
+			k:    Parameter,
+		},
+		{
+			name: "std-error",
+			err:  errors.New("test error"),
+			c:    Unknown,
+			k:    Other,
+		},
+		{
+			name: "nil",
+			c:    Unknown,
+			k:    Other,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, IsCodeOrKind(tt.err, tt.c, tt.k))
+		})
+	}
+}
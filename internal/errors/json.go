@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// jsonErr defines the JSON representation of an Err
+type jsonErr struct {
+	Code      Code              `json:"code"`
+	Kind      string            `json:"kind"`
+	Op        Op                `json:"op,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Message   string            `json:"message"`
+	Wrapped   string            `json:"wrapped,omitempty"`
+	Cause     string            `json:"cause,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	Timestamp *time.Time        `json:"timestamp,omitempty"`
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.  The message defaults
+// to the Code's Info().Message when the Err has no Msg (and is redacted when
+// WithRedactedMsg() was used), and only the wrapped error's and the Cause's
+// Error() are included (the errors themselves are never serialized).
+func (e *Err) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	info := e.Info()
+	j := jsonErr{
+		Code:      e.Code,
+		Kind:      info.Kind.WireString(),
+		Op:        e.Op,
+		RequestID: e.RequestID,
+		Message:   e.UserFacingMessage(),
+		Details:   e.Details,
+	}
+	if e.redactMsg {
+		j.Message = redacted
+	}
+	if !e.timestamp.IsZero() {
+		ts := e.timestamp
+		j.Timestamp = &ts
+	}
+	if e.Wrapped != nil {
+		j.Wrapped = e.Wrapped.Error()
+	}
+	if e.Cause != nil {
+		j.Cause = e.Cause.Error()
+	}
+	return json.Marshal(j)
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_MarshalJSON(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "code-only",
+			err:  New(NotUnique),
+			want: `{"code":1002,"kind":"integrity_violation","message":"must be unique violation"}`,
+		},
+		{
+			name: "op",
+			err:  New(NotUnique, WithOp("alice.Bob")),
+			want: `{"code":1002,"kind":"integrity_violation","op":"alice.Bob","message":"must be unique violation"}`,
+		},
+		{
+			name: "msg",
+			err:  New(NotUnique, WithMsg("test msg")),
+			want: `{"code":1002,"kind":"integrity_violation","message":"test msg"}`,
+		},
+		{
+			name: "op-msg-and-wrapped",
+			err:  New(InvalidParameter, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(errors.New("test error"))),
+			want: `{"code":100,"kind":"parameter_violation","op":"alice.Bob","message":"test msg","wrapped":"test error"}`,
+		},
+		{
+			name: "request-id",
+			err:  New(NotUnique, WithRequestID("r_1234567890")),
+			want: `{"code":1002,"kind":"integrity_violation","request_id":"r_1234567890","message":"must be unique violation"}`,
+		},
+		{
+			name: "details",
+			err:  New(NotUnique, WithDetails(map[string]string{"name": "alice"}), WithDetails(map[string]string{"id": "u_1234567890"})),
+			want: `{"code":1002,"kind":"integrity_violation","message":"must be unique violation","details":{"name":"alice","id":"u_1234567890"}}`,
+		},
+		{
+			name: "unregistered-code",
+			err:  New(Code(99999)),
+			want: `{"code":99999,"kind":"unknown","message":"unknown"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			// the timestamp is tested separately
+			got, err := json.Marshal(clearVolatile(tt.err))
+			require.NoError(err)
+			assert.JSONEq(tt.want, string(got))
+		})
+	}
+	t.Run("timestamp", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ts := time.Date(2020, 10, 14, 12, 30, 0, 0, time.UTC)
+		got, err := json.Marshal(New(NotUnique, WithClock(func() time.Time { return ts })))
+		require.NoError(err)
+		assert.JSONEq(`{"code":1002,"kind":"integrity_violation","message":"must be unique violation","timestamp":"2020-10-14T12:30:00Z"}`, string(got))
+	})
+	t.Run("nil", func(t *testing.T) {
+		var e *Err
+		got, err := json.Marshal(e)
+		require.NoError(t, err)
+		assert.Equal(t, "null", string(got))
+	})
+}
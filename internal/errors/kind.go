@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Kind specifies the kind of error (unknown, parameter, integrity, etc).
+type Kind uint32
+
+const (
+	Other Kind = iota
+	Parameter
+	Integrity
+	Search
+	Transaction
+	Interrupted
+)
+
+// Codes returns the built in and registered Codes of the Kind, sorted
+// numerically.  See CodesByKind.
+func (e Kind) Codes() []Code {
+	return CodesByKind(e)
+}
+
+// String returns the Kind's name (for example "integrity violation") or
+// Kind(N) when the Kind isn't defined.
+func (e Kind) String() string {
+	if name, ok := kindNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", uint32(e))
+}
+
+// valid returns true when the Kind is defined.
+func (e Kind) valid() bool {
+	_, ok := kindNames[e]
+	return ok
+}
+
+// kindNames provides a map of every defined Kind to its name.
+var kindNames = map[Kind]string{
+	Other:       "unknown",
+	Parameter:   "parameter violation",
+	Integrity:   "integrity violation",
+	Search:      "search issue",
+	Transaction: "transaction issue",
+	Interrupted: "interrupted operation",
+}
+
+// WireString returns the Kind's stable snake_case identifier for API
+// consumers (for example "integrity_violation"), or kind_N when the Kind
+// isn't defined.  Unlike String(), the identifiers are part of the API
+// contract and won't change:
+//
+//	Other       "unknown"
+//	Parameter   "parameter_violation"
+//	Integrity   "integrity_violation"
+//	Search      "search_issue"
+//	Transaction "transaction_issue"
+//	Interrupted "interrupted_operation"
+func (e Kind) WireString() string {
+	if name, ok := kindWireNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind_%d", uint32(e))
+}
+
+// kindWireNames provides a map of every defined Kind to its wire identifier.
+var kindWireNames = map[Kind]string{
+	Other:       "unknown",
+	Parameter:   "parameter_violation",
+	Integrity:   "integrity_violation",
+	Search:      "search_issue",
+	Transaction: "transaction_issue",
+	Interrupted: "interrupted_operation",
+}
+
+// AllKinds returns every defined Kind, in order.
+func AllKinds() []Kind {
+	kinds := make([]Kind, 0, len(kindNames))
+	for k := range kindNames {
+		kinds = append(kinds, k)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKind_String(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.Equal("unknown", Other.String())
+	assert.Equal("integrity violation", Integrity.String())
+	assert.Equal("interrupted operation", Interrupted.String())
+	assert.Equal("Kind(99999)", Kind(99999).String())
+	for _, k := range AllKinds() {
+		assert.NotEmpty(k.String())
+		assert.True(k.valid())
+	}
+	assert.False(Kind(99999).valid())
+}
+
+func TestAllKinds(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.Equal([]Kind{Other, Parameter, Integrity, Search, Transaction, Interrupted}, AllKinds())
+
+	// every Kind used by a Code is defined
+	for c := range codeNames {
+		assert.Contains(AllKinds(), c.Kind(), "%s has an undefined kind", c)
+	}
+}
+
+func TestInfo_IsKind(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.True(NotUnique.Info().IsKind(Integrity))
+	assert.False(NotUnique.Info().IsKind(Parameter))
+	assert.True(RecordNotFound.Info().IsKind(Search))
+	assert.True(Info{}.IsKind(Other))
+}
+
+func TestKind_WireString(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	// the wire strings are part of the API contract and must not change
+	want := map[Kind]string{
+		Other:       "unknown",
+		Parameter:   "parameter_violation",
+		Integrity:   "integrity_violation",
+		Search:      "search_issue",
+		Transaction: "transaction_issue",
+		Interrupted: "interrupted_operation",
+	}
+	assert.Len(want, len(AllKinds()), "every Kind must have a pinned wire string")
+	for _, k := range AllKinds() {
+		assert.Equal(want[k], k.WireString(), "wire string for %s", k)
+	}
+	assert.Equal("kind_99999", Kind(99999).WireString())
+}
@@ -0,0 +1,46 @@
+package errors
+
+import "sync"
+
+// messageCatalogsLock guards messageCatalogs, since a catalog may be set while
+// errors are being rendered.
+var messageCatalogsLock sync.RWMutex
+
+// messageCatalogs provides a map of languages to their catalog of localized
+// default messages for Codes.
+var messageCatalogs = map[string]map[Code]string{}
+
+// SetMessageCatalog sets the catalog of localized default messages for the
+// language lang, which are used in place of the Code's Info().Message by Errs
+// created WithLocale(lang).  Codes which are missing from the catalog fall
+// back to their Info().Message.  The messages are copied, and a nil or empty
+// map removes the catalog for the language.
+func SetMessageCatalog(lang string, messages map[Code]string) {
+	catalog := make(map[Code]string, len(messages))
+	for c, msg := range messages {
+		if msg != "" {
+			catalog[c] = msg
+		}
+	}
+	messageCatalogsLock.Lock()
+	defer messageCatalogsLock.Unlock()
+	if len(catalog) == 0 {
+		delete(messageCatalogs, lang)
+		return
+	}
+	messageCatalogs[lang] = catalog
+}
+
+// localizedMessage returns the Code's default message in the language lang,
+// falling back to the Code's Info().Message when there's no translation.
+func localizedMessage(c Code, lang string) string {
+	if lang != "" {
+		messageCatalogsLock.RLock()
+		msg, ok := messageCatalogs[lang][c]
+		messageCatalogsLock.RUnlock()
+		if ok {
+			return msg
+		}
+	}
+	return c.Info().Message
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMessageCatalog(t *testing.T) {
+	t.Parallel()
+	t.Run("localized", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		SetMessageCatalog("fr", map[Code]string{
+			NotUnique:      "doit être unique",
+			RecordNotFound: "enregistrement introuvable",
+		})
+		defer SetMessageCatalog("fr", nil)
+
+		err := New(NotUnique, WithLocale("fr"), WithoutStack()).(*Err)
+		assert.Equal("doit être unique", err.UserFacingMessage())
+		assert.Equal("doit être unique: integrity violation: error #1002", err.Error())
+		j, jErr := json.Marshal(err)
+		require.NoError(jErr)
+		assert.Contains(string(j), `"message":"doit être unique"`)
+
+		// English fallback for a code missing from the catalog
+		err = New(NotNull, WithLocale("fr"), WithoutStack()).(*Err)
+		assert.Equal("must not be empty (null) violation", err.UserFacingMessage())
+
+		// English fallback for a language without a catalog
+		err = New(NotUnique, WithLocale("de"), WithoutStack()).(*Err)
+		assert.Equal("must be unique violation", err.UserFacingMessage())
+
+		// English without a locale
+		err = New(NotUnique, WithoutStack()).(*Err)
+		assert.Equal("must be unique violation", err.UserFacingMessage())
+
+		// a Msg isn't localized
+		err = New(NotUnique, WithLocale("fr"), WithMsg("test msg"), WithoutStack()).(*Err)
+		assert.Equal("test msg", err.UserFacingMessage())
+	})
+	t.Run("remove", func(t *testing.T) {
+		assert := assert.New(t)
+		SetMessageCatalog("es", map[Code]string{NotUnique: "debe ser único"})
+		err := New(NotUnique, WithLocale("es"), WithoutStack()).(*Err)
+		assert.Equal("debe ser único", err.UserFacingMessage())
+
+		SetMessageCatalog("es", nil)
+		assert.Equal("must be unique violation", err.UserFacingMessage())
+	})
+	t.Run("copied", func(t *testing.T) {
+		assert := assert.New(t)
+		messages := map[Code]string{NotUnique: "deve essere unico"}
+		SetMessageCatalog("it", messages)
+		defer SetMessageCatalog("it", nil)
+		messages[NotUnique] = "changed"
+		assert.Equal("deve essere unico", New(NotUnique, WithLocale("it")).(*Err).UserFacingMessage())
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		defer SetMessageCatalog("nl", nil)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				SetMessageCatalog("nl", map[Code]string{NotUnique: "moet uniek zijn"})
+			}()
+			go func() {
+				defer wg.Done()
+				_ = New(NotUnique, WithLocale("nl"), WithoutStack()).Error()
+			}()
+		}
+		wg.Wait()
+	})
+}
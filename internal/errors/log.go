@@ -0,0 +1,41 @@
+package errors
+
+import "sort"
+
+// Args returns the Err's fields as key/value pairs suitable for hclog, for
+// example: logger.Error("operation failed", err.Args()...).  The op, msg,
+// request_id, caller and cause are omitted when empty, and each of the Details
+// follows as its own key/value pair (sorted by key).
+func (e *Err) Args() []interface{} {
+	if e == nil {
+		return nil
+	}
+	args := []interface{}{
+		"code", e.Code.String(),
+		"kind", e.Info().Kind.String(),
+	}
+	if e.Op != "" {
+		args = append(args, "op", string(e.Op))
+	}
+	if e.Msg != "" {
+		args = append(args, "msg", e.logMsg())
+	}
+	if e.RequestID != "" {
+		args = append(args, "request_id", e.RequestID)
+	}
+	if e.Caller != "" {
+		args = append(args, "caller", e.Caller)
+	}
+	if !isEmptyErr(e.Cause) {
+		args = append(args, "cause", e.Cause.Error())
+	}
+	keys := make([]string, 0, len(e.Details))
+	for k := range e.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k, e.Details[k])
+	}
+	return args
+}
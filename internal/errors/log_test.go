@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Args(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  *Err
+		want []interface{}
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "code-only",
+			err:  New(NotUnique).(*Err),
+			want: []interface{}{"code", "NotUnique", "kind", "integrity violation"},
+		},
+		{
+			name: "op",
+			err:  New(NotUnique, WithOp("alice.Bob")).(*Err),
+			want: []interface{}{"code", "NotUnique", "kind", "integrity violation", "op", "alice.Bob"},
+		},
+		{
+			name: "msg",
+			err:  New(NotUnique, WithMsg("test msg")).(*Err),
+			want: []interface{}{"code", "NotUnique", "kind", "integrity violation", "msg", "test msg"},
+		},
+		{
+			name: "all",
+			err:  New(InvalidParameter, WithOp("alice.Bob"), WithMsg("test msg"), WithWrap(ErrNotNull)).(*Err),
+			want: []interface{}{"code", "InvalidParameter", "kind", "parameter violation", "op", "alice.Bob", "msg", "test msg"},
+		},
+		{
+			name: "request-id",
+			err:  New(NotUnique, WithRequestID("r_1234567890")).(*Err),
+			want: []interface{}{"code", "NotUnique", "kind", "integrity violation", "request_id", "r_1234567890"},
+		},
+		{
+			name: "cause",
+			err:  New(NotUnique, WithCause(errors.New("connection refused")), WithWrap(ErrNotUnique)).(*Err),
+			want: []interface{}{"code", "NotUnique", "kind", "integrity violation", "cause", "connection refused"},
+		},
+		{
+			name: "details",
+			err:  New(NotUnique, WithDetails(map[string]string{"name": "alice", "id": "u_1234567890"})).(*Err),
+			want: []interface{}{"code", "NotUnique", "kind", "integrity violation", "id", "u_1234567890", "name", "alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.err.Args())
+		})
+	}
+}
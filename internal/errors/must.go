@@ -0,0 +1,22 @@
+package errors
+
+// Must returns err, and panics if err is nil.  It's for package variables and
+// init-time defaults which require an error, so a misconfiguration is caught
+// at startup:
+//
+//	var errDefault = errors.Must(errors.New(errors.InvalidParameter, errors.WithMsg("missing name")))
+func Must(err error) error {
+	if err == nil {
+		panic(New(InvalidParameter, WithOp("errors.Must"), WithMsg("missing error"), WithoutStack()))
+	}
+	return err
+}
+
+// MustCode panics if there's no Info for the Code c, because it's neither
+// built in nor registered with RegisterCode.  It's for init-time checks that a
+// Code used by a package is defined.
+func MustCode(c Code) {
+	if _, ok := lookupInfo(c); !ok {
+		panic(New(InvalidParameter, WithOp("errors.MustCode"), WithMsgf("code %d has no info", uint32(c)), WithoutStack()))
+	}
+}
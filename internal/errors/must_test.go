@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverPanic calls fn and returns the value it panicked with, if any.
+func recoverPanic(fn func()) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	fn()
+	return nil
+}
+
+func TestMust(t *testing.T) {
+	t.Parallel()
+	t.Run("error", func(t *testing.T) {
+		assert := assert.New(t)
+		want := New(InvalidParameter, WithMsg("missing name"))
+		var got error
+		assert.Nil(recoverPanic(func() { got = Must(want) }))
+		assert.Equal(want, got)
+	})
+	t.Run("nil", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		r := recoverPanic(func() { _ = Must(nil) })
+		require.NotNil(r)
+		err, ok := r.(*Err)
+		require.True(ok)
+		assert.Equal(InvalidParameter, err.Code)
+		assert.Equal("errors.Must: missing error: parameter violation: error #100", err.Error())
+	})
+}
+
+func TestMustCode(t *testing.T) {
+	t.Parallel()
+	t.Run("built-in", func(t *testing.T) {
+		assert := assert.New(t)
+		for c := range codeNames {
+			assert.Nil(recoverPanic(func() { MustCode(c) }), "%s panicked", c)
+		}
+	})
+	t.Run("registered", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const c = Code(53200)
+		defer unregisterCode(c)
+		require.NoError(RegisterCode(c, Info{Message: "plugin failure", Kind: Other}))
+		assert.Nil(recoverPanic(func() { MustCode(c) }))
+	})
+	t.Run("undefined", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		r := recoverPanic(func() { MustCode(Code(53201)) })
+		require.NotNil(r)
+		err, ok := r.(*Err)
+		require.True(ok)
+		assert.Equal("errors.MustCode: code 53201 has no info: parameter violation: error #100", err.Error())
+	})
+}
@@ -0,0 +1,30 @@
+package errors
+
+import "sync/atomic"
+
+// errorObserver holds the observer set by SetErrorObserver, wrapped in an
+// observer so a nil func can be stored.
+var errorObserver atomic.Value
+
+// observer wraps the func set by SetErrorObserver.
+type observer struct {
+	fn func(Code, Kind)
+}
+
+// SetErrorObserver sets a func which is called with the Code and Kind of
+// every Err created by New (and the other constructors, including Convert
+// when it produces an Err), for example to count errors by Code.  The func
+// must be safe for concurrent use and fast, since it's called synchronously.
+// Passing nil removes the observer.
+func SetErrorObserver(fn func(Code, Kind)) {
+	errorObserver.Store(observer{fn: fn})
+}
+
+// observe calls the observer, if one is set, for the Err.
+func observe(e *Err) {
+	o, ok := errorObserver.Load().(observer)
+	if !ok || o.fn == nil {
+		return
+	}
+	o.fn(e.Code, e.Info().Kind)
+}
@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSetErrorObserver isn't parallel, since the observer sees every Err
+// created while it's set.
+func TestSetErrorObserver(t *testing.T) {
+	assert := assert.New(t)
+	type observed struct {
+		code Code
+		kind Kind
+	}
+	var got []observed
+	SetErrorObserver(func(c Code, k Kind) {
+		got = append(got, observed{code: c, kind: k})
+	})
+	defer SetErrorObserver(nil)
+
+	_ = New(InvalidParameter)
+	_ = E(RecordNotFound)
+	_ = Convert(&pq.Error{Code: "23505"})
+	// not converted, so not observed
+	_ = Convert(&pq.Error{Code: "XX000"})
+	assert.Equal([]observed{
+		{code: InvalidParameter, kind: Parameter},
+		{code: RecordNotFound, kind: Search},
+		{code: NotUnique, kind: Integrity},
+	}, got)
+
+	got = nil
+	SetErrorObserver(nil)
+	_ = New(InvalidParameter)
+	assert.Empty(got)
+}
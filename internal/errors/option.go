@@ -0,0 +1,313 @@
+package errors
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetOpts - iterate the inbound Options and return a struct.
+func GetOpts(opt ...Option) Options {
+	opts := getDefaultOptions()
+	for _, o := range opt {
+		if o != nil {
+			o(&opts)
+		}
+	}
+	return opts
+}
+
+// Option - how Options are passed as arguments.
+type Option func(*Options)
+
+// Options - how Options are represented.
+type Options struct {
+	withErrWrapped     error
+	withErrMsg         string
+	withOp             Op
+	withCode           Code
+	withoutStack       bool
+	withDetails        map[string]string
+	withoutEvent       bool
+	withRedactedMsg    bool
+	withSeverity       Severity
+	withRequestID      string
+	withClock          func() time.Time
+	withLocale         string
+	withTransient      *bool
+	withInheritCode    bool
+	withMsgFromWrapped bool
+	withRetryAfter     time.Duration
+	withKind           *Kind
+	withHTTPStatus     int
+	withCause          error
+	withSuppressKind   bool
+	withSuppressCode   bool
+}
+
+func getDefaultOptions() Options {
+	return Options{}
+}
+
+// WithWrap provides an option to provide an error to wrap when creating a
+// new error.  A nil error (including a nil *Err) is ignored, so the new error
+// doesn't wrap anything.
+func WithWrap(e error) Option {
+	return func(o *Options) {
+		o.withErrWrapped = nilIfEmpty(e)
+	}
+}
+
+// WithCause provides an option to provide the root cause of the new error,
+// which is reported separately from the wrapped error (see Err.Cause) and
+// doesn't change the chain walked by errors.Is() and errors.As().
+func WithCause(e error) Option {
+	return func(o *Options) {
+		o.withCause = nilIfEmpty(e)
+	}
+}
+
+// WithWrapf provides an option to provide an error to wrap along with a
+// formatted message annotating it when creating a new error.  Like WithWrap, a
+// nil error is ignored but the message is still used.  When used with WithMsg
+// or WithRedactedMsg, the last option wins.
+func WithWrapf(e error, format string, args ...interface{}) Option {
+	return func(o *Options) {
+		o.withErrWrapped = nilIfEmpty(e)
+		o.withErrMsg = fmt.Sprintf(format, args...)
+		o.withRedactedMsg = false
+	}
+}
+
+// WithWraps provides an option to provide multiple errors to wrap when
+// creating a new error.  Nil errors (including nil *Errs) are ignored.  Since
+// it sets the same wrapped error as WithWrap, the last option wins when both
+// are used.
+func WithWraps(errs ...error) Option {
+	return func(o *Options) {
+		o.withErrWrapped = newWrappedErrors(errs...)
+	}
+}
+
+// WithMsg provides an option to provide a message when creating a new
+// error.
+func WithMsg(msg string) Option {
+	return func(o *Options) {
+		o.withErrMsg = msg
+		o.withRedactedMsg = false
+	}
+}
+
+// WithMsgf provides an option to provide a formatted message when creating a
+// new error.  When used with WithMsg, the last option wins.
+func WithMsgf(format string, args ...interface{}) Option {
+	return func(o *Options) {
+		o.withErrMsg = fmt.Sprintf(format, args...)
+		o.withRedactedMsg = false
+	}
+}
+
+// WithRedactedMsg provides an option to provide a message which may contain
+// sensitive data (for example, values echoed by the database) when creating a
+// new error.  The message is replaced by "[redacted]" in Error(), Args(),
+// DebugString() and MarshalJSON(), but is returned by UserFacingMessage().
+// When used with WithMsg or WithMsgf, the last option wins.
+func WithRedactedMsg(msg string) Option {
+	return func(o *Options) {
+		o.withErrMsg = msg
+		o.withRedactedMsg = true
+	}
+}
+
+// WithOp provides an option to provide the operation that's raising or
+// propagating the error.
+func WithOp(op Op) Option {
+	return func(o *Options) {
+		o.withOp = op
+	}
+}
+
+// WithCode provides an option to provide a Code which takes precedence over
+// the Code passed to New (or the Code chosen by Convert).  An Unknown Code is
+// ignored, so the positional Code is used.
+func WithCode(c Code) Option {
+	return func(o *Options) {
+		o.withCode = c
+	}
+}
+
+// WithoutStack provides an option to skip capturing the call stack when
+// creating a new error, which is useful for hot paths.
+func WithoutStack() Option {
+	return func(o *Options) {
+		o.withoutStack = true
+	}
+}
+
+// WithDetails provides an option to provide structured details when creating
+// a new error.  Details from multiple WithDetails options are merged and the
+// last value for a key wins.
+func WithDetails(kv map[string]string) Option {
+	return func(o *Options) {
+		if len(kv) == 0 {
+			return
+		}
+		if o.withDetails == nil {
+			o.withDetails = make(map[string]string, len(kv))
+		}
+		for k, v := range kv {
+			o.withDetails[k] = v
+		}
+	}
+}
+
+// extraFieldKey is the Details key WithFields uses for the value at the end of
+// an odd number of key/value pairs, like hclog.
+const extraFieldKey = "EXTRA_VALUE_AT_END"
+
+// WithFields provides an option to provide structured details as hclog style
+// key/value pairs (for example WithFields("name", name, "scope_id", scopeId))
+// when creating a new error.  Keys and values are stringified with fmt.Sprint
+// and merged like WithDetails.  When there's an odd number of pairs, the value
+// at the end is stored with the key "EXTRA_VALUE_AT_END", rather than being
+// dropped.
+func WithFields(kv ...interface{}) Option {
+	return func(o *Options) {
+		if len(kv) == 0 {
+			return
+		}
+		if o.withDetails == nil {
+			o.withDetails = make(map[string]string, (len(kv)+1)/2)
+		}
+		for i := 0; i+1 < len(kv); i += 2 {
+			o.withDetails[fmt.Sprint(kv[i])] = fmt.Sprint(kv[i+1])
+		}
+		if len(kv)%2 != 0 {
+			o.withDetails[extraFieldKey] = fmt.Sprint(kv[len(kv)-1])
+		}
+	}
+}
+
+// WithoutEvent provides an option to mark the new error as one which
+// shouldn't trigger an error event.
+func WithoutEvent() Option {
+	return func(o *Options) {
+		o.withoutEvent = true
+	}
+}
+
+// WithSeverity provides an option to override the default Severity (derived
+// from the Kind) when creating a new error.
+func WithSeverity(s Severity) Option {
+	return func(o *Options) {
+		o.withSeverity = s
+	}
+}
+
+// WithRequestID provides an option to provide the id of the request which
+// raised the new error.
+func WithRequestID(id string) Option {
+	return func(o *Options) {
+		o.withRequestID = id
+	}
+}
+
+// WithClock provides an option to provide the clock used to capture the
+// timestamp when creating a new error, which allows tests to use a
+// deterministic clock.  The default is time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(o *Options) {
+		if clock != nil {
+			o.withClock = clock
+		}
+	}
+}
+
+// WithLocale provides an option to provide the language of the default
+// message (see SetMessageCatalog) when creating a new error.
+func WithLocale(lang string) Option {
+	return func(o *Options) {
+		o.withLocale = lang
+	}
+}
+
+// WithTransient provides an option to override whether the error is transient
+// (see Err.Transient), rather than using the default for its Kind.
+func WithTransient(transient bool) Option {
+	return func(o *Options) {
+		o.withTransient = &transient
+	}
+}
+
+// WithKind provides an option to override the Kind of the new error's Code,
+// for a generic Code whose Kind depends on the context it's used in.  The
+// override is returned by the error's Info(), so it's used by Error(),
+// GetKind(), HTTPStatus(), etc, and can be any Kind including Other.
+func WithKind(k Kind) Option {
+	return func(o *Options) {
+		o.withKind = &k
+	}
+}
+
+// WithHTTPStatus provides an option to override the HTTP status code returned
+// by HTTPStatus() for the new error, rather than deriving it from the Kind (for
+// example 403 for an authorization failure with a generic Code).  A code
+// which isn't a valid HTTP status (outside 100-599) is ignored, since
+// http.ResponseWriter.WriteHeader panics for it.
+func WithHTTPStatus(code int) Option {
+	return func(o *Options) {
+		if code < 100 || code > 599 {
+			return
+		}
+		o.withHTTPStatus = code
+	}
+}
+
+// WithInheritCode provides an option to use the first Code other than Unknown
+// of the Errs in the wrapped error's chain when the new error's Code is
+// Unknown, so a meaningful Code isn't masked by an Unknown one (including an
+// Unknown Err in the middle of the chain).  A Code other than Unknown always
+// takes precedence.
+func WithInheritCode() Option {
+	return func(o *Options) {
+		o.withInheritCode = true
+	}
+}
+
+// WithRetryAfter provides an option to provide how long a client should wait
+// before retrying the failed request, which is returned to HTTP clients in the
+// Retry-After header (see Handler).
+func WithRetryAfter(d time.Duration) Option {
+	return func(o *Options) {
+		o.withRetryAfter = d
+	}
+}
+
+// WithSuppressKind provides an option to omit the Kind from the new error's
+// Error(), for log pipelines which want a minimal string.  It doesn't change
+// the error's other representations (Args(), MarshalJSON(), etc).
+func WithSuppressKind() Option {
+	return func(o *Options) {
+		o.withSuppressKind = true
+	}
+}
+
+// WithSuppressCodeNumber provides an option to omit the "error #N" code number
+// from the new error's Error(), like WithSuppressKind.
+func WithSuppressCodeNumber() Option {
+	return func(o *Options) {
+		o.withSuppressCode = true
+	}
+}
+
+// WithMsgFromWrapped provides an option to use the wrapped error's message as
+// the new error's Msg when no msg is specified, so a message which is safe to
+// show doesn't need to be repeated.  When the wrapped error's chain contains an
+// *Err, its UserFacingMessage() is used (and it stays redacted if it was
+// created with WithRedactedMsg), since its Error() includes its Op and Code.
+// Otherwise the wrapped error's Error() is used.  An explicit msg always takes
+// precedence.
+func WithMsgFromWrapped() Option {
+	return func(o *Options) {
+		o.withMsgFromWrapped = true
+	}
+}
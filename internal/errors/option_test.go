@@ -0,0 +1,425 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_getOpts provides unit tests for GetOpts and all the options
+func Test_getOpts(t *testing.T) {
+	t.Parallel()
+	t.Run("WithMsg", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withErrMsg = ""
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithMsg("test msg"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "test msg"
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithWrap", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withErrWrapped = nil
+		assert.Equal(opts, testOpts)
+
+		err := errors.New("test error")
+		opts = GetOpts(WithWrap(err))
+		testOpts = getDefaultOptions()
+		testOpts.withErrWrapped = err
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithWrapf", func(t *testing.T) {
+		assert := assert.New(t)
+		err := errors.New("test error")
+		opts := GetOpts(WithWrapf(err, "unable to %s", "create"))
+		testOpts := getDefaultOptions()
+		testOpts.withErrWrapped = err
+		testOpts.withErrMsg = "unable to create"
+		assert.Equal(opts, testOpts)
+
+		// last one wins, including over a redacted msg
+		opts = GetOpts(WithRedactedMsg("secret"), WithWrapf(nil, "public"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "public"
+		assert.Equal(opts, testOpts)
+		assert.Equal("public: unknown: error #0", New(Unknown, WithRedactedMsg("secret"), WithWrapf(nil, "public")).Error())
+	})
+	t.Run("WithWraps", func(t *testing.T) {
+		assert := assert.New(t)
+		err1, err2 := errors.New("test error 1"), errors.New("test error 2")
+
+		opts := GetOpts(WithWraps())
+		testOpts := getDefaultOptions()
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithWraps(nil, err1, nil))
+		testOpts = getDefaultOptions()
+		testOpts.withErrWrapped = err1
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithWraps(err1, nil, err2))
+		testOpts = getDefaultOptions()
+		testOpts.withErrWrapped = wrappedErrors{err1, err2}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithOp", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withOp = ""
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithOp("alice.bob"))
+		testOpts = getDefaultOptions()
+		testOpts.withOp = "alice.bob"
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithCode", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withCode = Unknown
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithCode(NotUnique))
+		testOpts = getDefaultOptions()
+		testOpts.withCode = NotUnique
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithMsgf", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := GetOpts(WithMsgf("%s: %d", "alice", 1))
+		testOpts := getDefaultOptions()
+		testOpts.withErrMsg = "alice: 1"
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithMsgf(""))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = ""
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithMsgf("no verbs"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "no verbs"
+		assert.Equal(opts, testOpts)
+
+		// last one wins
+		opts = GetOpts(WithMsg("alice"), WithMsgf("%s", "bob"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "bob"
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithMsgf("%s", "bob"), WithMsg("alice"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "alice"
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithoutStack", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withoutStack = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithoutStack())
+		testOpts = getDefaultOptions()
+		testOpts.withoutStack = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithDetails", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withDetails = nil
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithDetails(nil))
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(
+			WithDetails(map[string]string{"name": "alice", "scope": "global"}),
+			WithDetails(map[string]string{"name": "bob", "id": "u_1234567890"}),
+		)
+		testOpts = getDefaultOptions()
+		testOpts.withDetails = map[string]string{"name": "bob", "scope": "global", "id": "u_1234567890"}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithFields", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withDetails = nil
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithFields())
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithFields("name", "alice", "count", 2, Op("op"), NotUnique))
+		testOpts = getDefaultOptions()
+		testOpts.withDetails = map[string]string{"name": "alice", "count": "2", "op": "NotUnique"}
+		assert.Equal(opts, testOpts)
+
+		// merged with WithDetails and the last value for a key wins
+		opts = GetOpts(
+			WithDetails(map[string]string{"name": "alice", "scope": "global"}),
+			WithFields("name", "bob", "id", "u_1234567890"),
+		)
+		testOpts = getDefaultOptions()
+		testOpts.withDetails = map[string]string{"name": "bob", "scope": "global", "id": "u_1234567890"}
+		assert.Equal(opts, testOpts)
+
+		// odd arity
+		opts = GetOpts(WithFields("name", "alice", "u_1234567890"))
+		testOpts = getDefaultOptions()
+		testOpts.withDetails = map[string]string{"name": "alice", "EXTRA_VALUE_AT_END": "u_1234567890"}
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithFields("alice"))
+		testOpts = getDefaultOptions()
+		testOpts.withDetails = map[string]string{"EXTRA_VALUE_AT_END": "alice"}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithoutEvent", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withoutEvent = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithoutEvent())
+		testOpts = getDefaultOptions()
+		testOpts.withoutEvent = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithRedactedMsg", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withRedactedMsg = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithRedactedMsg("secret"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "secret"
+		testOpts.withRedactedMsg = true
+		assert.Equal(opts, testOpts)
+
+		// last one wins
+		opts = GetOpts(WithRedactedMsg("secret"), WithMsg("test msg"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "test msg"
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithMsgf("%s", "test msg"), WithRedactedMsg("secret"))
+		testOpts = getDefaultOptions()
+		testOpts.withErrMsg = "secret"
+		testOpts.withRedactedMsg = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithSeverity", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withSeverity = UnknownSeverity
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithSeverity(SeverityCritical))
+		testOpts = getDefaultOptions()
+		testOpts.withSeverity = SeverityCritical
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithRequestID", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withRequestID = ""
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithRequestID("r_1234567890"))
+		testOpts = getDefaultOptions()
+		testOpts.withRequestID = "r_1234567890"
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithLocale", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withLocale = ""
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithLocale("fr"))
+		testOpts = getDefaultOptions()
+		testOpts.withLocale = "fr"
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithTransient", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withTransient = nil
+		assert.Equal(opts, testOpts)
+
+		transient := true
+		opts = GetOpts(WithTransient(true))
+		testOpts = getDefaultOptions()
+		testOpts.withTransient = &transient
+		assert.Equal(opts, testOpts)
+
+		permanent := false
+		opts = GetOpts(WithTransient(true), WithTransient(false))
+		testOpts = getDefaultOptions()
+		testOpts.withTransient = &permanent
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithKind", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withKind = nil
+		assert.Equal(opts, testOpts)
+
+		search := Search
+		opts = GetOpts(WithKind(Search))
+		testOpts = getDefaultOptions()
+		testOpts.withKind = &search
+		assert.Equal(opts, testOpts)
+
+		other := Other
+		opts = GetOpts(WithKind(Search), WithKind(Other))
+		testOpts = getDefaultOptions()
+		testOpts.withKind = &other
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithHTTPStatus", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withHTTPStatus = 0
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithHTTPStatus(403))
+		testOpts = getDefaultOptions()
+		testOpts.withHTTPStatus = 403
+		assert.Equal(opts, testOpts)
+
+		// invalid statuses are ignored
+		for _, code := range []int{-1, 42, 99, 600, 1200} {
+			opts = GetOpts(WithHTTPStatus(code))
+			testOpts = getDefaultOptions()
+			assert.Equal(opts, testOpts, "status %d", code)
+		}
+	})
+	t.Run("WithCause", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withCause = nil
+		assert.Equal(opts, testOpts)
+
+		cause := errors.New("connection refused")
+		opts = GetOpts(WithCause(cause))
+		testOpts = getDefaultOptions()
+		testOpts.withCause = cause
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithInheritCode", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withInheritCode = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithInheritCode())
+		testOpts = getDefaultOptions()
+		testOpts.withInheritCode = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithRetryAfter", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withRetryAfter = 0
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithRetryAfter(30 * time.Second))
+		testOpts = getDefaultOptions()
+		testOpts.withRetryAfter = 30 * time.Second
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithSuppressKind", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withSuppressKind = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithSuppressKind())
+		testOpts = getDefaultOptions()
+		testOpts.withSuppressKind = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithSuppressCodeNumber", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withSuppressCode = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithSuppressCodeNumber())
+		testOpts = getDefaultOptions()
+		testOpts.withSuppressCode = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithMsgFromWrapped", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		testOpts := getDefaultOptions()
+		testOpts.withMsgFromWrapped = false
+		assert.Equal(opts, testOpts)
+
+		opts = GetOpts(WithMsgFromWrapped())
+		testOpts = getDefaultOptions()
+		testOpts.withMsgFromWrapped = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithClock", func(t *testing.T) {
+		assert := assert.New(t)
+		// test default
+		opts := GetOpts()
+		assert.Nil(opts.withClock)
+
+		ts := time.Date(2020, 10, 14, 12, 30, 0, 0, time.UTC)
+		opts = GetOpts(WithClock(func() time.Time { return ts }))
+		assert.NotNil(opts.withClock)
+		assert.Equal(ts, opts.withClock())
+	})
+}
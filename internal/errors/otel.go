@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The attributes RecordError sets on a span.
+const (
+	spanCodeKey = attribute.Key("code")
+	spanKindKey = attribute.Key("kind")
+	spanOpKey   = attribute.Key("op")
+)
+
+// RecordError sets the status of the span to Error, with the user-facing
+// message of the first *Err in the error's chain (see UserFacingMessage), and
+// records its Code, Kind (see Kind.WireString) and Op as the span's "code",
+// "kind" and "op" attributes, so traces are consistent with the error
+// taxonomy.  The op attribute is omitted when the Err has no Op.  Errors which
+// don't contain an *Err are recorded as an Unknown error, like ToAPIError.
+// Nothing is recorded for nil or when the span isn't recording.
+func RecordError(span trace.Span, err error) {
+	if err == nil || span == nil || !span.IsRecording() {
+		return
+	}
+	e := firstErr(err)
+	if e == nil {
+		e = &Err{Code: Unknown}
+	}
+	attrs := []attribute.KeyValue{
+		spanCodeKey.String(e.Code.String()),
+		spanKindKey.String(e.Info().Kind.WireString()),
+	}
+	if e.Op != "" {
+		attrs = append(attrs, spanOpKey.String(string(e.Op)))
+	}
+	span.SetAttributes(attrs...)
+	span.SetStatus(otelcodes.Error, e.UserFacingMessage())
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecordError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus sdktrace.Status
+		wantAttrs  []attribute.KeyValue
+	}{
+		{
+			name:       "err",
+			err:        New(NotUnique, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithRequestID("r_1234567890")),
+			wantStatus: sdktrace.Status{Code: otelcodes.Error, Description: "name is already in use"},
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("code", "NotUnique"),
+				attribute.String("kind", "integrity_violation"),
+				attribute.String("op", "iam.CreateRole"),
+			},
+		},
+		{
+			name:       "redacted-msg",
+			err:        New(NotUnique, WithRedactedMsg("name alice is already in use")),
+			wantStatus: sdktrace.Status{Code: otelcodes.Error, Description: "name alice is already in use"},
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("code", "NotUnique"),
+				attribute.String("kind", "integrity_violation"),
+			},
+		},
+		{
+			name:       "wrapped-err",
+			err:        Wrap(New(RecordNotFound, WithOp("db.LookupById")), "iam.LookupRole", RecordNotFound),
+			wantStatus: sdktrace.Status{Code: otelcodes.Error, Description: "record not found"},
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("code", "RecordNotFound"),
+				attribute.String("kind", "search_issue"),
+				attribute.String("op", "iam.LookupRole"),
+			},
+		},
+		{
+			name:       "std-err",
+			err:        errors.New("test error"),
+			wantStatus: sdktrace.Status{Code: otelcodes.Error, Description: "unknown"},
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("code", "Unknown"),
+				attribute.String("kind", "unknown"),
+			},
+		},
+		{
+			name:       "nil",
+			wantStatus: sdktrace.Status{Code: otelcodes.Unset},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			sr := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+			_, span := tp.Tracer("errors").Start(context.Background(), "test")
+			RecordError(span, tt.err)
+			span.End()
+
+			spans := sr.Ended()
+			require.Len(spans, 1)
+			assert.Equal(tt.wantStatus, spans[0].Status())
+			assert.Equal(tt.wantAttrs, spans[0].Attributes())
+		})
+	}
+	t.Run("non-recording", func(t *testing.T) {
+		assert := assert.New(t)
+		span := trace.SpanFromContext(context.Background())
+		assert.False(span.IsRecording())
+		assert.NotPanics(func() { RecordError(span, New(NotUnique)) })
+		assert.NotPanics(func() { RecordError(nil, New(NotUnique)) })
+	})
+}
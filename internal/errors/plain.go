@@ -0,0 +1,32 @@
+package errors
+
+// Plain returns an error for handing to code outside Boundary (for example a
+// third-party library which does its own formatting), whose Error() is just
+// the user-facing message of the first *Err in the error's chain (see
+// UserFacingMessage), without the Kind, Code, Op or wrapped errors.  The
+// returned error unwraps to err, so errors.Is() and errors.As() still match
+// the original.  An error which doesn't contain an *Err is already plain and
+// is returned as is, and nil is returned for nil.
+func Plain(err error) error {
+	e := firstErr(err)
+	if e == nil {
+		return err
+	}
+	return &plainError{msg: e.UserFacingMessage(), err: err}
+}
+
+// plainError is the error returned by Plain.
+type plainError struct {
+	msg string
+	err error
+}
+
+// Error returns the user-facing message.
+func (e *plainError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the original error.
+func (e *plainError) Unwrap() error {
+	return e.err
+}
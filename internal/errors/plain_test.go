@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlain(t *testing.T) {
+	t.Parallel()
+	stdErr := errors.New("test error")
+	tests := []struct {
+		name    string
+		err     error
+		wantMsg string
+		wantIs  error
+	}{
+		{
+			name:    "msg",
+			err:     New(NotUnique, WithOp("iam.CreateRole"), WithMsg("name is already in use"), WithWrap(stdErr)),
+			wantMsg: "name is already in use",
+			wantIs:  ErrNotUnique,
+		},
+		{
+			name:    "default-msg",
+			err:     New(RecordNotFound, WithOp("iam.LookupRole")),
+			wantMsg: "record not found",
+			wantIs:  ErrRecordNotFound,
+		},
+		{
+			name:    "redacted-msg",
+			err:     New(NotUnique, WithRedactedMsg("name alice is already in use")),
+			wantMsg: "name alice is already in use",
+			wantIs:  ErrNotUnique,
+		},
+		{
+			name:    "wrapped-err",
+			err:     fmt.Errorf("unable to create role: %w", New(NotUnique, WithMsg("name is already in use"))),
+			wantMsg: "name is already in use",
+			wantIs:  ErrNotUnique,
+		},
+		{
+			name:    "std-err",
+			err:     stdErr,
+			wantMsg: "test error",
+			wantIs:  stdErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got := Plain(tt.err)
+			assert.Equal(tt.wantMsg, got.Error())
+			assert.True(errors.Is(got, tt.wantIs))
+			assert.True(errors.Is(got, tt.err))
+			assert.Equal(GetCode(tt.err), GetCode(got))
+		})
+	}
+	t.Run("stdErr-in-chain", func(t *testing.T) {
+		assert := assert.New(t)
+		got := Plain(New(NotUnique, WithWrap(stdErr)))
+		assert.True(errors.Is(got, stdErr))
+		var e *Err
+		assert.True(errors.As(got, &e))
+		assert.Equal(NotUnique, e.Code)
+	})
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, Plain(nil))
+	})
+}
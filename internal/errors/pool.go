@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the largest capacity of a buffer which is returned to
+// bufferPool, so rendering an unusually large error doesn't pin its memory.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers used to render errors, so logging errors at a
+// high rate doesn't allocate and grow a new buffer for every call to Error().
+// A bytes.Buffer is used rather than a strings.Builder since String() copies
+// its contents, which allows the buffer to be reused once it's been reset.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from bufferPool.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer resets b and returns it to bufferPool.  b must not be used, or
+// retained, after it's been returned.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
@@ -0,0 +1,31 @@
+package errors
+
+import "fmt"
+
+// RecoverToError recovers a panic and replaces the error errp points to with
+// an Unknown Err describing the panic, and supports the same options as New.
+// It must be deferred, and only sets the error when a panic occurred and the
+// error is nil.  A panic which occurs when the error isn't nil is still
+// recovered, but the error is returned unchanged.  The panic isn't recovered
+// when errp is nil.  When the panic value is an error, the Err wraps it.
+//
+//	func (s Service) CreateRole(ctx context.Context, req *pbs.CreateRoleRequest) (_ *pbs.CreateRoleResponse, retErr error) {
+//		defer errors.RecoverToError(&retErr, errors.WithOp("roles.(Service).CreateRole"))
+//		...
+//	}
+func RecoverToError(errp *error, opt ...Option) {
+	if errp == nil {
+		return
+	}
+	r := recover()
+	if r == nil || *errp != nil {
+		return
+	}
+	panicOpts := []Option{WithMsg(fmt.Sprintf("panic: %v", r))}
+	if err, ok := r.(error); ok {
+		panicOpts = append(panicOpts, WithWrap(err))
+	}
+	// skip runtime.Callers, callers, newErr and RecoverToError, so the stack
+	// starts at the panic
+	*errp = newErr(4, Unknown, append(panicOpts, opt...)...)
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverToError(t *testing.T) {
+	t.Parallel()
+	run := func(fn func() error, opt ...Option) (err error) {
+		defer RecoverToError(&err, opt...)
+		return fn()
+	}
+	t.Run("no-panic", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.NoError(run(func() error { return nil }))
+		want := New(NotNull)
+		assert.Equal(want, run(func() error { return want }))
+	})
+	t.Run("panic", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := run(func() error { panic("boom") }, WithOp("alice.Bob"))
+		require.Error(err)
+		assert.Equal(&Err{Code: Unknown, Op: "alice.Bob", Msg: "panic: boom"}, clearVolatile(err))
+		assert.Equal("alice.Bob: panic: boom: unknown: error #0", err.Error())
+		assert.NotEmpty(err.(*Err).stack)
+		assert.Contains(fmt.Sprintf("%+v", err), "recover_test.go")
+	})
+	t.Run("panic-error", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		panicErr := errors.New("test error")
+		err := run(func() error { panic(panicErr) }, WithoutStack())
+		require.Error(err)
+		assert.Equal(&Err{Code: Unknown, Msg: "panic: test error", Wrapped: panicErr}, clearVolatile(err))
+		assert.True(errors.Is(err, panicErr))
+		assert.Empty(err.(*Err).stack)
+	})
+	t.Run("panic-runtime-error", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := run(func() error {
+			var m map[string]int
+			m["boom"] = 1
+			return nil
+		})
+		require.Error(err)
+		assert.Contains(err.Error(), "panic: assignment to entry in nil map")
+		assert.Equal(Unknown, GetCode(err))
+	})
+	t.Run("existing-error", func(t *testing.T) {
+		assert := assert.New(t)
+		want := New(NotNull)
+		err := func() (err error) {
+			defer RecoverToError(&err)
+			err = want
+			panic("boom")
+		}()
+		assert.Equal(want, err)
+		assert.Equal(NotNull, GetCode(err))
+	})
+	t.Run("nil-errp", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.PanicsWithValue("boom", func() {
+			defer RecoverToError(nil)
+			panic("boom")
+		})
+	})
+}
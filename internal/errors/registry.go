@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+)
+
+// errorCodeInfoLock guards errorCodeInfo, since RegisterCode may add to it
+// while it's being read.  All access to errorCodeInfo must go through
+// lookupInfo, codeInfo, RegisterCode, CodesByKind and Catalog.
+var errorCodeInfoLock sync.RWMutex
+
+// RegisterCode registers the Info for a Code which isn't built in, so it's
+// resolved by Info() like any other Code.  It returns an error if the Code is
+// already built in or registered, or the Info has no Message.
+func RegisterCode(c Code, info Info) error {
+	const op = "errors.RegisterCode"
+	if info.Message == "" {
+		return New(InvalidParameter, WithOp(op), WithMsgf("missing message for code %d", uint32(c)), WithoutStack())
+	}
+	if _, ok := codeNames[c]; ok {
+		return New(InvalidParameter, WithOp(op), WithMsgf("code %d is built in as %s", uint32(c), c), WithoutStack())
+	}
+	errorCodeInfoLock.Lock()
+	defer errorCodeInfoLock.Unlock()
+	if _, ok := errorCodeInfo[c]; ok {
+		return New(NotUnique, WithOp(op), WithMsgf("code %d is already registered", uint32(c)), WithoutStack())
+	}
+	errorCodeInfo[c] = info
+	return nil
+}
+
+// CodesByKind returns the built in and registered Codes of the Kind k, sorted
+// numerically.
+func CodesByKind(k Kind) []Code {
+	errorCodeInfoLock.RLock()
+	defer errorCodeInfoLock.RUnlock()
+	var codes []Code
+	for c, info := range errorCodeInfo {
+		if info.Kind == k {
+			codes = append(codes, c)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// Catalog returns the Info of every built in and registered Code.  The map is
+// a copy, so it may be modified without affecting the registry.
+func Catalog() map[Code]Info {
+	errorCodeInfoLock.RLock()
+	defer errorCodeInfoLock.RUnlock()
+	catalog := make(map[Code]Info, len(errorCodeInfo))
+	for c, info := range errorCodeInfo {
+		catalog[c] = info
+	}
+	return catalog
+}
+
+// minRangeCode is the first Code which can be reserved with ReserveRange,
+// since lower Codes are reserved for built in Codes.
+const minRangeCode = Code(2000)
+
+// codeRangesLock guards codeRanges.
+var codeRangesLock sync.Mutex
+
+// codeRanges provides a map of the names of ranges reserved by ReserveRange
+// to their range.
+var codeRanges = map[string]*codeRange{}
+
+// codeRange is a range of Codes reserved by ReserveRange.
+type codeRange struct {
+	start, end Code
+
+	// next is the next Code NewCode will try to hand out.
+	next uint64
+}
+
+// ReserveRange reserves the Codes from start to end (inclusive) as the range
+// name, so NewCode can hand out Codes from it.  It returns an error if the
+// name is already reserved, or the range is invalid, includes Codes below
+// 2000 (which are reserved for built in Codes) or overlaps a reserved range.
+func ReserveRange(name string, start, end Code) error {
+	const op = "errors.ReserveRange"
+	switch {
+	case name == "":
+		return New(InvalidParameter, WithOp(op), WithMsg("missing range name"), WithoutStack())
+	case start > end:
+		return New(InvalidParameter, WithOp(op), WithMsgf("range %q starts (%d) after it ends (%d)", name, uint32(start), uint32(end)), WithoutStack())
+	case start < minRangeCode:
+		return New(InvalidParameter, WithOp(op), WithMsgf("range %q starts (%d) below %d", name, uint32(start), uint32(minRangeCode)), WithoutStack())
+	}
+	codeRangesLock.Lock()
+	defer codeRangesLock.Unlock()
+	if _, ok := codeRanges[name]; ok {
+		return New(NotUnique, WithOp(op), WithMsgf("range %q is already reserved", name), WithoutStack())
+	}
+	for n, r := range codeRanges {
+		if start <= r.end && r.start <= end {
+			return New(NotUnique, WithOp(op), WithMsgf("range %q overlaps range %q (%d-%d)", name, n, uint32(r.start), uint32(r.end)), WithoutStack())
+		}
+	}
+	codeRanges[name] = &codeRange{start: start, end: end, next: uint64(start)}
+	return nil
+}
+
+// NewCode returns a Code from the range reserved as rangeName which hasn't
+// been handed out before or registered, which can then be registered with
+// RegisterCode.  It returns an error if the range isn't reserved or all of its
+// Codes are used.
+func NewCode(rangeName string) (Code, error) {
+	const op = "errors.NewCode"
+	codeRangesLock.Lock()
+	defer codeRangesLock.Unlock()
+	r, ok := codeRanges[rangeName]
+	if !ok {
+		return Unknown, New(RecordNotFound, WithOp(op), WithMsgf("range %q isn't reserved", rangeName), WithoutStack())
+	}
+	for ; r.next <= uint64(r.end); r.next++ {
+		c := Code(r.next)
+		if _, registered := lookupInfo(c); !registered {
+			r.next++
+			return c, nil
+		}
+	}
+	return Unknown, New(Unknown, WithOp(op), WithMsgf("range %q is exhausted", rangeName), WithoutStack())
+}
+
+// lookupInfo returns the Info for the Code and whether it was found.
+func lookupInfo(c Code) (Info, bool) {
+	errorCodeInfoLock.RLock()
+	defer errorCodeInfoLock.RUnlock()
+	info, ok := errorCodeInfo[c]
+	return info, ok
+}
+
+// codeInfo returns the Info for the Code or the Info for an Unknown Code if
+// it's not found.
+func codeInfo(c Code) Info {
+	errorCodeInfoLock.RLock()
+	defer errorCodeInfoLock.RUnlock()
+	if info, ok := errorCodeInfo[c]; ok {
+		return info
+	}
+	return errorCodeInfo[Unknown]
+}
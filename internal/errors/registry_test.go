@@ -0,0 +1,295 @@
+package errors
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unregisterCode removes a Code registered by RegisterCode during a test.
+func unregisterCode(c Code) {
+	errorCodeInfoLock.Lock()
+	defer errorCodeInfoLock.Unlock()
+	delete(errorCodeInfo, c)
+}
+
+func TestRegisterCode(t *testing.T) {
+	t.Parallel()
+	t.Run("valid", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const c = Code(50000)
+		defer unregisterCode(c)
+		info := Info{Message: "plugin failure", Kind: Integrity}
+		require.NoError(RegisterCode(c, info))
+		assert.Equal(info, c.Info())
+		assert.Equal(info, New(c).(*Err).Info())
+		assert.Equal(Integrity, GetKind(New(c)))
+		assert.Equal("plugin failure: integrity violation: error #50000", New(c).Error())
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const c = Code(50001)
+		defer unregisterCode(c)
+		require.NoError(RegisterCode(c, Info{Message: "plugin failure", Kind: Integrity}))
+		err := RegisterCode(c, Info{Message: "other plugin failure", Kind: Parameter})
+		require.Error(err)
+		assert.True(Match(&Err{Code: NotUnique}, err))
+		assert.Equal("plugin failure", c.Info().Message)
+	})
+	t.Run("built-in", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		err := RegisterCode(NotUnique, Info{Message: "plugin failure", Kind: Parameter})
+		require.Error(err)
+		assert.True(Match(&Err{Code: InvalidParameter}, err))
+		assert.Equal(codeInfo(NotUnique), NotUnique.Info())
+	})
+	t.Run("missing-message", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const c = Code(50002)
+		err := RegisterCode(c, Info{Kind: Parameter})
+		require.Error(err)
+		assert.True(Match(&Err{Code: InvalidParameter}, err))
+		assert.Equal(codeInfo(Unknown), c.Info())
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		assert := assert.New(t)
+		const base, count = Code(51000), 50
+		info := Info{Message: "plugin failure", Kind: Search}
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			c := base + Code(i)
+			defer unregisterCode(c)
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(RegisterCode(c, info))
+			}()
+			go func() {
+				defer wg.Done()
+				_ = c.Info()
+				_ = New(c).Error()
+			}()
+		}
+		wg.Wait()
+		for i := 0; i < count; i++ {
+			assert.Equal(info, (base + Code(i)).Info())
+		}
+	})
+}
+
+// unreserveRange removes a range reserved by ReserveRange during a test.
+func unreserveRange(name string) {
+	codeRangesLock.Lock()
+	defer codeRangesLock.Unlock()
+	delete(codeRanges, name)
+}
+
+func TestReserveRange(t *testing.T) {
+	t.Parallel()
+	t.Run("valid", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		defer unreserveRange("test-valid")
+		require.NoError(ReserveRange("test-valid", 60000, 60099))
+		c, err := NewCode("test-valid")
+		require.NoError(err)
+		assert.Equal(Code(60000), c)
+		c, err = NewCode("test-valid")
+		require.NoError(err)
+		assert.Equal(Code(60001), c)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			start, end Code
+		}{
+			{name: "", start: 60100, end: 60199},
+			{name: "test-reversed", start: 60199, end: 60100},
+			{name: "test-built-in", start: NotUnique, end: 60199},
+		}
+		for _, tt := range tests {
+			err := ReserveRange(tt.name, tt.start, tt.end)
+			assert.True(t, Match(&Err{Code: InvalidParameter}, err), "%s: %v", tt.name, err)
+		}
+	})
+	t.Run("overlap", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		defer unreserveRange("test-overlap")
+		require.NoError(ReserveRange("test-overlap", 60200, 60299))
+		for _, r := range [][2]Code{{60200, 60299}, {60150, 60200}, {60299, 60350}, {60250, 60260}, {60100, 60400}} {
+			err := ReserveRange("test-other", r[0], r[1])
+			assert.True(Match(&Err{Code: NotUnique}, err), "%d-%d: %v", r[0], r[1], err)
+		}
+		err := ReserveRange("test-overlap", 60300, 60399)
+		assert.True(Match(&Err{Code: NotUnique}, err))
+
+		// adjacent ranges don't overlap
+		defer unreserveRange("test-adjacent")
+		assert.NoError(ReserveRange("test-adjacent", 60300, 60399))
+	})
+	t.Run("exhausted", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		defer unreserveRange("test-exhausted")
+		require.NoError(ReserveRange("test-exhausted", 60400, 60402))
+
+		// registered codes aren't handed out
+		defer unregisterCode(60401)
+		require.NoError(RegisterCode(60401, Info{Message: "plugin failure", Kind: Integrity}))
+
+		var got []Code
+		for i := 0; i < 2; i++ {
+			c, err := NewCode("test-exhausted")
+			require.NoError(err)
+			got = append(got, c)
+		}
+		assert.Equal([]Code{60400, 60402}, got)
+		c, err := NewCode("test-exhausted")
+		assert.Error(err)
+		assert.Equal(Unknown, c)
+	})
+	t.Run("max-code", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const max = Code(^uint32(0))
+		defer unreserveRange("test-max")
+		require.NoError(ReserveRange("test-max", max, max))
+		c, err := NewCode("test-max")
+		require.NoError(err)
+		assert.Equal(max, c)
+		_, err = NewCode("test-max")
+		assert.Error(err)
+	})
+	t.Run("not-reserved", func(t *testing.T) {
+		assert := assert.New(t)
+		c, err := NewCode("test-missing")
+		assert.True(Match(&Err{Code: RecordNotFound}, err))
+		assert.Equal(Unknown, c)
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		defer unreserveRange("test-concurrent")
+		require.NoError(ReserveRange("test-concurrent", 60500, 60599))
+		var wg sync.WaitGroup
+		codes := make(chan Code, 100)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c, err := NewCode("test-concurrent")
+				assert.NoError(err)
+				codes <- c
+			}()
+		}
+		wg.Wait()
+		close(codes)
+		seen := map[Code]bool{}
+		for c := range codes {
+			assert.False(seen[c], "duplicate code %d", c)
+			seen[c] = true
+		}
+		assert.Len(seen, 100)
+	})
+}
+
+func TestCodesByKind(t *testing.T) {
+	t.Parallel()
+	t.Run("built-in", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal([]Code{Timeout, Cancelled, ConnectionFailure}, CodesByKind(Interrupted))
+		assert.Equal([]Code{TransactionRetryable}, CodesByKind(Transaction))
+		assert.Equal([]Code{Timeout, Cancelled, ConnectionFailure}, Interrupted.Codes())
+		assert.Empty(CodesByKind(Kind(1000)))
+	})
+	t.Run("registry", func(t *testing.T) {
+		assert := assert.New(t)
+		for c := range codeNames {
+			kind := c.Kind()
+			assert.Contains(CodesByKind(kind), c)
+			codes := kind.Codes()
+			for i, got := range codes {
+				assert.Equal(kind, got.Kind())
+				if i > 0 {
+					assert.Less(uint32(codes[i-1]), uint32(got))
+				}
+			}
+		}
+	})
+	t.Run("registered", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const c = Code(53000)
+		defer unregisterCode(c)
+		require.NoError(RegisterCode(c, Info{Message: "plugin timeout", Kind: Interrupted}))
+		assert.Equal([]Code{Timeout, Cancelled, ConnectionFailure, c}, CodesByKind(Interrupted))
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	t.Parallel()
+	t.Run("built-in", func(t *testing.T) {
+		assert := assert.New(t)
+		catalog := Catalog()
+		for c := range codeNames {
+			assert.Contains(catalog, c)
+			assert.Equal(c.Info(), catalog[c])
+		}
+	})
+	t.Run("registered", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		const c = Code(53100)
+		defer unregisterCode(c)
+		info := Info{Message: "plugin not found", Kind: Search}
+		require.NoError(RegisterCode(c, info))
+		assert.Equal(info, Catalog()[c])
+	})
+	t.Run("copy", func(t *testing.T) {
+		assert := assert.New(t)
+		catalog := Catalog()
+		catalog[NotUnique] = Info{Message: "changed", Kind: Other}
+		delete(catalog, RecordNotFound)
+		catalog[Code(53101)] = Info{Message: "added", Kind: Other}
+
+		assert.Equal(Info{Message: "must be unique violation", Kind: Integrity}, NotUnique.Info())
+		assert.Equal(Search, RecordNotFound.Kind())
+		_, ok := lookupInfo(Code(53101))
+		assert.False(ok)
+		assert.NotEqual(catalog, Catalog())
+	})
+}
+
+func TestCodeInfo_Race(t *testing.T) {
+	t.Parallel()
+	const base, count, readers = Code(52000), 100, 20
+	info := Info{Message: "plugin failure", Kind: Parameter}
+	defer func() {
+		for i := 0; i < count; i++ {
+			unregisterCode(base + Code(i))
+		}
+	}()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := New(base+Code(i), WithoutStack())
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					_ = err.(*Err).Info()
+					_ = err.Error()
+					_ = GetKind(err)
+					_ = NotUnique.Info()
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < count; i++ {
+		assert.NoError(t, RegisterCode(base+Code(i), info))
+	}
+	close(done)
+	wg.Wait()
+	assert.Equal(t, info, (base + Code(readers-1)).Info())
+}
@@ -0,0 +1,135 @@
+package errors
+
+// sentinel is the sentinel error for a Code.  An *Err which doesn't wrap
+// another error wraps its Code's sentinel (see Err.Unwrap), and any *Err
+// matches its Code's sentinel with errors.Is (see Err.Is), even when it wraps
+// another error.
+type sentinel struct {
+	code Code
+	msg  string
+}
+
+// Error satisfies the error interface and returns the sentinel's message, or
+// the Code's default message when it doesn't have one.
+func (s *sentinel) Error() string {
+	if s.msg != "" {
+		return s.msg
+	}
+	return s.code.Info().Message
+}
+
+// codeSentinels provides a map of Codes to their sentinel errors.  It's only
+// written while initializing the package's sentinel variables.
+var codeSentinels = map[Code]error{}
+
+// newSentinel creates the sentinel error for the Code, with an optional
+// message which is used instead of the Code's default message.  The sentinels
+// which predate the rest keep their original messages, so the Error() of the
+// Errs which wrap them doesn't change.
+func newSentinel(c Code, msg ...string) error {
+	s := &sentinel{code: c}
+	if len(msg) > 0 {
+		s.msg = msg[0]
+	}
+	codeSentinels[c] = s
+	return s
+}
+
+// Sentinel returns the Code's sentinel error, which matches an *Err with the
+// Code via errors.Is.  Codes registered with RegisterCode get a new sentinel
+// on each call, which still matches with errors.Is.
+func (c Code) Sentinel() error {
+	if s, ok := codeSentinels[c]; ok {
+		return s
+	}
+	return &sentinel{code: c}
+}
+
+// kindSentinel is the sentinel error for a Kind.  An *Err matches its Code's
+// Kind sentinel with errors.Is.
+type kindSentinel struct {
+	kind Kind
+}
+
+// Error satisfies the error interface and returns the Kind's String().
+func (s *kindSentinel) Error() string {
+	return s.kind.String()
+}
+
+// Errors returned from this package may be tested against these errors with
+// errors.Is, to match any Code of the Kind.
+var (
+	// ErrKindOther is the sentinel error for the Other Kind.
+	ErrKindOther error = &kindSentinel{kind: Other}
+
+	// ErrKindParameter is the sentinel error for the Parameter Kind.
+	ErrKindParameter error = &kindSentinel{kind: Parameter}
+
+	// ErrKindIntegrity is the sentinel error for the Integrity Kind.
+	ErrKindIntegrity error = &kindSentinel{kind: Integrity}
+
+	// ErrKindSearch is the sentinel error for the Search Kind.
+	ErrKindSearch error = &kindSentinel{kind: Search}
+
+	// ErrKindTransaction is the sentinel error for the Transaction Kind.
+	ErrKindTransaction error = &kindSentinel{kind: Transaction}
+
+	// ErrKindInterrupted is the sentinel error for the Interrupted Kind.
+	ErrKindInterrupted error = &kindSentinel{kind: Interrupted}
+)
+
+// Errors returned from this package may be tested against these errors
+// with errors.Is, and there's one for every Code.
+var (
+	// ErrUnknown is the sentinel error for Unknown.
+	ErrUnknown = newSentinel(Unknown)
+
+	// ErrInvalidParameter is the sentinel error for InvalidParameter.
+	ErrInvalidParameter = newSentinel(InvalidParameter)
+
+	// ErrTimeout is the sentinel error for Timeout.
+	ErrTimeout = newSentinel(Timeout)
+
+	// ErrCancelled is the sentinel error for Cancelled.
+	ErrCancelled = newSentinel(Cancelled)
+
+	// ErrMultipleErrors is the sentinel error for MultipleErrors.
+	ErrMultipleErrors = newSentinel(MultipleErrors)
+
+	// ErrCheckConstraint is returned by methods when a write to the repository
+	// resulted in a check constraint violation
+	ErrCheckConstraint = newSentinel(CheckConstraint, "check constraint violated")
+
+	// ErrNotNull is returned by methods when a write to the repository resulted
+	// in a not null constraint violation
+	ErrNotNull = newSentinel(NotNull, "not null constraint violated")
+
+	// ErrNotUnique is returned by create and update methods when a write
+	// to the repository resulted in a unique constraint violation.
+	ErrNotUnique = newSentinel(NotUnique, "unique constraint violation")
+
+	// ErrNotSpecificIntegrity is the sentinel error for NotSpecificIntegrity.
+	ErrNotSpecificIntegrity = newSentinel(NotSpecificIntegrity)
+
+	// ErrMissingTable is the sentinel error for MissingTable.
+	ErrMissingTable = newSentinel(MissingTable)
+
+	// ErrForeignKeyViolation is returned by methods when a write to the
+	// repository resulted in a foreign key constraint violation
+	ErrForeignKeyViolation = newSentinel(ForeignKeyViolation, "foreign key constraint violated")
+
+	// ErrTransactionRetryable is the sentinel error for TransactionRetryable.
+	ErrTransactionRetryable = newSentinel(TransactionRetryable)
+
+	// ErrValueTooLong is the sentinel error for ValueTooLong.
+	ErrValueTooLong = newSentinel(ValueTooLong)
+
+	// ErrConnectionFailure is the sentinel error for ConnectionFailure.
+	ErrConnectionFailure = newSentinel(ConnectionFailure)
+
+	// ErrRecordNotFound is the sentinel error for RecordNotFound.
+	ErrRecordNotFound = newSentinel(RecordNotFound)
+
+	// ErrMultipleRecords is the sentinel error for MultipleRecords.
+	ErrMultipleRecords = newSentinel(MultipleRecords)
+)
@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKind_Sentinel(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	assert.True(errors.Is(New(NotUnique), ErrKindIntegrity))
+	assert.True(errors.Is(fmt.Errorf("wrapped: %w", New(NotUnique)), ErrKindIntegrity))
+	assert.False(errors.Is(New(NotUnique), ErrKindParameter))
+	assert.False(errors.Is(New(NotUnique), ErrKindSearch))
+	assert.True(errors.Is(New(ValueTooLong), ErrKindParameter))
+	assert.True(errors.Is(New(RecordNotFound), ErrKindSearch))
+	assert.True(errors.Is(New(TransactionRetryable), ErrKindTransaction))
+	assert.True(errors.Is(New(Code(99999)), ErrKindOther))
+	assert.False(errors.Is(errors.New("test error"), ErrKindOther))
+	assert.False(errors.Is(ErrNotUnique, ErrKindIntegrity))
+	assert.Equal("integrity violation", ErrKindIntegrity.Error())
+
+	// an outer Err matches its own Kind, and the wrapped Err's
+	err := New(InvalidParameter, WithWrap(New(RecordNotFound)))
+	assert.True(errors.Is(err, ErrKindParameter))
+	assert.True(errors.Is(err, ErrKindSearch))
+}
+
+func TestCode_Sentinel(t *testing.T) {
+	t.Parallel()
+	t.Run("every-code", func(t *testing.T) {
+		assert := assert.New(t)
+		for c := range codeNames {
+			s, ok := codeSentinels[c]
+			assert.True(ok, "missing sentinel for %s", c)
+			assert.Equal(s, c.Sentinel())
+			assert.True(errors.Is(New(c), s), "%s doesn't match its sentinel", c)
+			assert.True(errors.Is(fmt.Errorf("wrapped: %w", New(c)), s), "wrapped %s doesn't match its sentinel", c)
+			for other := range codeNames {
+				if other != c {
+					assert.False(errors.Is(New(other), s), "%s matches the sentinel for %s", other, c)
+				}
+			}
+		}
+	})
+	t.Run("variables", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.True(errors.Is(New(InvalidParameter), ErrInvalidParameter))
+		assert.True(errors.Is(New(RecordNotFound), ErrRecordNotFound))
+		assert.True(errors.Is(New(NotUnique), ErrNotUnique))
+		assert.False(errors.Is(New(NotUnique), ErrNotNull))
+		assert.False(errors.Is(errors.New("test error"), ErrUnknown))
+	})
+	t.Run("messages", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal("unique constraint violation", ErrNotUnique.Error())
+		assert.Equal("not null constraint violated", ErrNotNull.Error())
+		assert.Equal("check constraint violated", ErrCheckConstraint.Error())
+		assert.Equal("foreign key constraint violated", ErrForeignKeyViolation.Error())
+		assert.Equal(InvalidParameter.Info().Message, ErrInvalidParameter.Error())
+		assert.Equal(RecordNotFound.Info().Message, ErrRecordNotFound.Error())
+	})
+	t.Run("wrapped", func(t *testing.T) {
+		assert := assert.New(t)
+		// an Err which doesn't wrap anything wraps its Code's sentinel, which
+		// isn't part of its Error()
+		err := New(InvalidParameter, WithMsg("missing name")).(*Err)
+		assert.Equal(ErrInvalidParameter, errors.Unwrap(err))
+		assert.Nil(err.Wrapped)
+		assert.Equal("missing name: parameter violation: error #100", err.Error())
+		assert.Equal(ErrRecordNotFound, errors.Unwrap(&Err{Code: RecordNotFound}))
+
+		// a supplied wrapped error is used instead
+		wrapped := errors.New("test error")
+		assert.Equal(wrapped, errors.Unwrap(New(InvalidParameter, WithWrap(wrapped))))
+		assert.True(errors.Is(New(InvalidParameter, WithWrap(wrapped)), ErrInvalidParameter))
+
+		// Convert supplies the sentinel itself for integrity violations
+		converted := Convert(&pq.Error{Code: "23505"})
+		assert.Equal(ErrNotUnique, errors.Unwrap(converted))
+		assert.Equal(ErrNotNull, errors.Unwrap(Convert(&pq.Error{Code: "23502", Column: "name"})))
+
+		// an unregistered code doesn't have a sentinel to wrap
+		assert.Nil(errors.Unwrap(New(Code(99999))))
+	})
+	t.Run("unregistered-code", func(t *testing.T) {
+		assert := assert.New(t)
+		c := Code(99999)
+		assert.True(errors.Is(New(c), c.Sentinel()))
+		assert.False(errors.Is(New(NotUnique), c.Sentinel()))
+	})
+}
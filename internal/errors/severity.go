@@ -0,0 +1,59 @@
+package errors
+
+import "fmt"
+
+// Severity specifies how severe an error is, ordered from least to most
+// severe.
+type Severity uint32
+
+const (
+	// UnknownSeverity is the zero value and means no Severity was specified.
+	UnknownSeverity Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// String returns the Severity's name (for example "warning") or Severity(N)
+// when the Severity isn't defined.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", uint32(s))
+}
+
+// severityNames provides a map of every defined Severity to its name.
+var severityNames = map[Severity]string{
+	UnknownSeverity:  "unknown",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// kindSeverity provides a map of Kinds to their default Severity.
+var kindSeverity = map[Kind]Severity{
+	Other:       SeverityError,
+	Parameter:   SeverityInfo,
+	Integrity:   SeverityCritical,
+	Search:      SeverityInfo,
+	Transaction: SeverityWarning,
+	Interrupted: SeverityWarning,
+}
+
+// Severity returns the Err's Severity, which is the Severity provided via
+// WithSeverity() or the default Severity for the Err's Kind.
+func (e *Err) Severity() Severity {
+	if e == nil {
+		return UnknownSeverity
+	}
+	if e.severity != UnknownSeverity {
+		return e.severity
+	}
+	if s, ok := kindSeverity[e.Info().Kind]; ok {
+		return s
+	}
+	return SeverityError
+}
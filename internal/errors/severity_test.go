@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Severity(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  *Err
+		want Severity
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: UnknownSeverity,
+		},
+		{
+			name: "unknown",
+			err:  New(Unknown).(*Err),
+			want: SeverityError,
+		},
+		{
+			name: "parameter",
+			err:  New(InvalidParameter).(*Err),
+			want: SeverityInfo,
+		},
+		{
+			name: "search",
+			err:  New(RecordNotFound).(*Err),
+			want: SeverityInfo,
+		},
+		{
+			name: "integrity",
+			err:  New(CheckConstraint).(*Err),
+			want: SeverityCritical,
+		},
+		{
+			name: "transaction",
+			err:  New(TransactionRetryable).(*Err),
+			want: SeverityWarning,
+		},
+		{
+			name: "override",
+			err:  New(NotUnique, WithSeverity(SeverityInfo)).(*Err),
+			want: SeverityInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.err.Severity())
+		})
+	}
+	t.Run("every-kind", func(t *testing.T) {
+		for k := range kindSeverity {
+			assert.NotEqual(t, UnknownSeverity, kindSeverity[k])
+		}
+		for c := range codeNames {
+			assert.NotEqual(t, UnknownSeverity, New(c).(*Err).Severity(), "missing severity for %s", c)
+		}
+	})
+	t.Run("ordering", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.True(SeverityInfo < SeverityWarning)
+		assert.True(SeverityWarning < SeverityError)
+		assert.True(SeverityError < SeverityCritical)
+		assert.Equal("critical", SeverityCritical.String())
+	})
+	t.Run("string", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal("unknown", UnknownSeverity.String())
+		assert.Equal("info", SeverityInfo.String())
+		assert.Equal("warning", SeverityWarning.String())
+		assert.Equal("error", SeverityError.String())
+		assert.Equal("critical", SeverityCritical.String())
+		assert.Equal("Severity(42)", Severity(42).String())
+	})
+}
@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/jackc/pgconn"
+	"github.com/lib/pq"
+)
+
+// pgError contains the fields of a Postgres error which are common to the
+// supported drivers (lib/pq and pgx/pgconn), so the SQLSTATE handling in
+// convertPgError is shared regardless of driver.
+type pgError struct {
+	Code       string
+	Message    string
+	Detail     string
+	Column     string
+	Constraint string
+	Table      string
+	Hint       string
+	Position   string
+	Where      string
+}
+
+// class returns the SQLSTATE class, which is the first two characters of its
+// code.
+func (p pgError) class() string {
+	if len(p.Code) < 2 {
+		return p.Code
+	}
+	return p.Code[0:2]
+}
+
+// debugDetails returns the position, hint and where of the error, which are
+// added to the converted Err's Details as "position", "hint" and "where" to
+// help debugging failures in complex queries.  Empty fields are skipped.
+func (p pgError) debugDetails() map[string]string {
+	details := map[string]string{}
+	if p.Position != "" {
+		details["position"] = p.Position
+	}
+	if p.Hint != "" {
+		details["hint"] = p.Hint
+	}
+	if p.Where != "" {
+		details["where"] = p.Where
+	}
+	return details
+}
+
+// asPgError finds the first *pq.Error or *pgconn.PgError in the error's chain
+// and returns its fields.
+func asPgError(e error) (pgError, bool) {
+	var pqError *pq.Error
+	if errors.As(e, &pqError) {
+		return pgError{
+			Code:       string(pqError.Code),
+			Message:    pqError.Message,
+			Detail:     pqError.Detail,
+			Column:     pqError.Column,
+			Constraint: pqError.Constraint,
+			Table:      pqError.Table,
+			Hint:       pqError.Hint,
+			Position:   pqError.Position,
+			Where:      pqError.Where,
+		}, true
+	}
+	var pgconnError *pgconn.PgError
+	if errors.As(e, &pgconnError) {
+		var position string
+		if pgconnError.Position != 0 {
+			position = strconv.Itoa(int(pgconnError.Position))
+		}
+		return pgError{
+			Code:       pgconnError.Code,
+			Message:    pgconnError.Message,
+			Detail:     pgconnError.Detail,
+			Column:     pgconnError.ColumnName,
+			Constraint: pgconnError.ConstraintName,
+			Table:      pgconnError.TableName,
+			Hint:       pgconnError.Hint,
+			Position:   position,
+			Where:      pgconnError.Where,
+		}, true
+	}
+	return pgError{}, false
+}
+
+// uniqueDetailRe matches the detail of a unique_violation, for example
+// "Key (name)=(alice) already exists." or "Key (scope_id, name)=(p_1234567890,
+// alice) already exists."
+var uniqueDetailRe = regexp.MustCompile(`^Key \((.+?)\)=\((.*)\) already exists\.$`)
+
+// parseUniqueDetail returns the column(s) and conflicting value(s) of a
+// unique_violation's detail, or false if the detail doesn't match the format
+// Postgres uses.
+func parseUniqueDetail(detail string) (column, value string, ok bool) {
+	m := uniqueDetailRe.FindStringSubmatch(detail)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// convertPgError converts the Postgres error e, whose fields are pgErr, to an
+// *Err based on its SQLSTATE.  The SQLSTATEs which are known individually are
+// tried first, and then the SQLSTATE's class, so whole families of errors are
+// converted.  It returns nil if neither the SQLSTATE nor its class is handled.
+// The error's debug details (see debugDetails) are added to every converted
+// Err, before the caller's options.  The skip is passed to newErr, so the Err's
+// stack and Caller start at the code which called Convert.
+func convertPgError(skip int, e error, pgErr pgError, opt []Option) error {
+	opt = append([]Option{WithDetails(pgErr.debugDetails())}, opt...)
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		// the detail echoes the conflicting values, so the msg is redacted
+		// and only the column is included in the details.  The constraint is
+		// the name of the unique constraint or index (which may be a partial
+		// or expression index), so it's included too.
+		details := map[string]string{}
+		if pgErr.Constraint != "" {
+			details["constraint"] = pgErr.Constraint
+		}
+		column, value, ok := parseUniqueDetail(pgErr.Detail)
+		if !ok {
+			return newErr(skip, NotUnique, convertOpts(opt, convertRedactedMsg(NotUnique, pgErr.Detail), WithDetails(details), convertWrap(e, ErrNotUnique))...)
+		}
+		details["column"] = column
+		msg := fmt.Sprintf("%s %q is already in use", column, value)
+		return newErr(skip, NotUnique, convertOpts(opt, convertRedactedMsg(NotUnique, msg), WithDetails(details), convertWrap(e, ErrNotUnique))...)
+	case "23502": // not_null_violation
+		return newErr(skip, NotNull, convertOpts(opt, convertMsgf(NotNull, "%s must not be empty", pgErr.Column), convertWrap(e, ErrNotNull))...)
+	case "23514": // check_violation
+		return newErr(skip, CheckConstraint, convertOpts(opt, convertMsgf(CheckConstraint, "%s constraint failed", pgErr.Constraint), convertWrap(e, ErrCheckConstraint))...)
+	case "23P01": // exclusion_violation
+		// the detail echoes the conflicting values, so only the constraint is
+		// used
+		if pgErr.Constraint == "" {
+			return newErr(skip, NotSpecificIntegrity, convertOpts(opt, convertMsg(NotSpecificIntegrity, pgErr.Message), convertWrap(e, nil))...)
+		}
+		return newErr(skip, NotSpecificIntegrity, convertOpts(opt, convertMsgf(NotSpecificIntegrity, "%s exclusion constraint failed", pgErr.Constraint), WithDetails(map[string]string{"constraint": pgErr.Constraint}), convertWrap(e, nil))...)
+	case "23503": // foreign_key_violation
+		return newErr(skip, ForeignKeyViolation, convertOpts(opt, convertMsgf(ForeignKeyViolation, "%s constraint failed for %s", pgErr.Constraint, pgErr.Table), convertWrap(e, ErrForeignKeyViolation))...)
+	case "22001": // string_data_right_truncation
+		if pgErr.Column != "" {
+			return newErr(skip, ValueTooLong, convertOpts(opt, convertMsgf(ValueTooLong, "%s value is too long", pgErr.Column), WithWrap(e))...)
+		}
+		return newErr(skip, ValueTooLong, convertOpts(opt, convertMsg(ValueTooLong, pgErr.Message), WithWrap(e))...)
+	case "22P02": // invalid_text_representation
+		if pgErr.Column != "" {
+			return newErr(skip, InvalidParameter, convertOpts(opt, convertMsgf(InvalidParameter, "%s value is invalid: %s", pgErr.Column, pgErr.Message), WithWrap(e))...)
+		}
+		return newErr(skip, InvalidParameter, convertOpts(opt, convertMsg(InvalidParameter, pgErr.Message), WithWrap(e))...)
+	case "22003": // numeric_value_out_of_range
+		if pgErr.Column != "" {
+			return newErr(skip, InvalidParameter, convertOpts(opt, convertMsgf(InvalidParameter, "%s value is out of range: %s", pgErr.Column, pgErr.Message), WithWrap(e))...)
+		}
+		return newErr(skip, InvalidParameter, convertOpts(opt, convertMsg(InvalidParameter, pgErr.Message), WithWrap(e))...)
+	case "57014": // query_canceled, which is returned when a statement_timeout fires
+		return newErr(skip, Timeout, convertOpts(opt, convertMsg(Timeout, pgErr.Message), WithWrap(e))...)
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return newErr(skip, TransactionRetryable, convertOpts(opt, convertMsg(TransactionRetryable, pgErr.Message), WithWrap(e))...)
+	case "42P01": // undefined_table
+		return newErr(skip, MissingTable, convertOpts(opt, convertMsg(MissingTable, pgErr.Message), convertWrap(e, nil))...)
+	}
+
+	switch pgErr.class() {
+	case "23": // integrity_constraint_violation
+		return newErr(skip, NotSpecificIntegrity, convertOpts(opt, convertMsg(NotSpecificIntegrity, pgErr.Message), convertWrap(e, nil))...)
+	case "22": // data_exception
+		return newErr(skip, InvalidParameter, convertOpts(opt, convertMsg(InvalidParameter, pgErr.Message), WithWrap(e))...)
+	case "40": // transaction_rollback
+		return newErr(skip, TransactionRetryable, convertOpts(opt, convertMsg(TransactionRetryable, pgErr.Message), WithWrap(e))...)
+	case "08": // connection_exception
+		return newErr(skip, ConnectionFailure, convertOpts(opt, convertMsg(ConnectionFailure, pgErr.Message), WithWrap(e))...)
+	}
+	return nil
+}
+
+// convertWrap returns the option for the error wrapped by an Err converted from
+// e, which is the sentinel (or nothing when it's nil) unless e contains an
+// *Err.  An *Err in e (for example an Unknown Err wrapping the driver error)
+// is wrapped instead, so its Op, Msg, etc aren't discarded by the conversion.
+func convertWrap(e error, sentinel error) Option {
+	switch {
+	case firstErr(e) != nil:
+		return WithWrap(e)
+	case sentinel != nil:
+		return WithWrap(sentinel)
+	default:
+		return nil
+	}
+}
+
+// convertMsgTransformer holds the transformer set by
+// SetConvertMsgTransformer, wrapped in a msgTransformer so a nil func can be
+// stored.
+var convertMsgTransformer atomic.Value
+
+// msgTransformer wraps the func set by SetConvertMsgTransformer.
+type msgTransformer struct {
+	fn func(Code, string) string
+}
+
+// SetConvertMsgTransformer sets a func which Convert applies to every message
+// it builds from a database error, with the converted Code, before the Err is
+// created.  This centralizes message hygiene, for example stripping schema or
+// table prefixes which reveal internal naming.  Messages provided by the
+// caller's options aren't transformed.  The func must be safe for concurrent
+// use, and passing nil removes the transformer.
+func SetConvertMsgTransformer(fn func(Code, string) string) {
+	convertMsgTransformer.Store(msgTransformer{fn: fn})
+}
+
+// transformConvertMsg returns the transformer set by SetConvertMsgTransformer,
+// or nil if there isn't one.
+func transformConvertMsg() func(Code, string) string {
+	t, _ := convertMsgTransformer.Load().(msgTransformer)
+	return t.fn
+}
+
+// convertMsg returns the option for a msg built by Convert, which is
+// transformed by the transformer set by SetConvertMsgTransformer.
+func convertMsg(c Code, msg string) Option {
+	if fn := transformConvertMsg(); fn != nil {
+		msg = fn(c, msg)
+	}
+	return WithMsg(msg)
+}
+
+// convertMsgf returns the option for a formatted msg built by Convert, which is
+// transformed like convertMsg.
+func convertMsgf(c Code, format string, args ...interface{}) Option {
+	return convertMsg(c, fmt.Sprintf(format, args...))
+}
+
+// convertRedactedMsg returns the option for a redacted msg built by Convert,
+// which is transformed like convertMsg.
+func convertRedactedMsg(c Code, msg string) Option {
+	if fn := transformConvertMsg(); fn != nil {
+		msg = fn(c, msg)
+	}
+	return WithRedactedMsg(msg)
+}
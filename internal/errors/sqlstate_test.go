@@ -0,0 +1,388 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/boundary/internal/errors/errortest"
+	"github.com/jackc/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConvertError_Class(t *testing.T) {
+	t.Parallel()
+	pgErr := func(code, msg string) *pgconn.PgError {
+		return &pgconn.PgError{Code: code, Message: msg}
+	}
+	exclusionErr := pgErr("23P01", "conflicting key value violates exclusion constraint")
+	divisionErr := pgErr("22012", "division by zero")
+	datetimeErr := pgErr("22007", `invalid input syntax for type timestamp: "yesterday-ish"`)
+	tooLongErr := pgErr("22001", "value too long for type character varying(10)")
+	txIntegrityErr := pgErr("40002", "transaction integrity constraint violation")
+	completionErr := pgErr("40003", "statement completion unknown")
+	serializationErr := pgErr("40001", "could not serialize access")
+	connFailureErr := pgErr("08006", "connection failure")
+	noConnErr := pgErr("08003", "connection does not exist")
+	syntaxErr := pgErr("42601", `syntax error at or near "SELEC"`)
+	tests := []struct {
+		name string
+		e    error
+		want error
+	}{
+		{
+			name: "integrity-exclusion-violation",
+			e:    exclusionErr,
+			want: New(NotSpecificIntegrity, WithMsg("conflicting key value violates exclusion constraint")),
+		},
+		{
+			name: "data-exception-division-by-zero",
+			e:    divisionErr,
+			want: New(InvalidParameter, WithMsg("division by zero"), WithWrap(divisionErr)),
+		},
+		{
+			name: "data-exception-invalid-datetime-format",
+			e:    datetimeErr,
+			want: New(InvalidParameter, WithMsg(`invalid input syntax for type timestamp: "yesterday-ish"`), WithWrap(datetimeErr)),
+		},
+		{
+			name: "data-exception-specific-code-first",
+			e:    tooLongErr,
+			want: New(ValueTooLong, WithMsg("value too long for type character varying(10)"), WithWrap(tooLongErr)),
+		},
+		{
+			name: "transaction-rollback-integrity",
+			e:    txIntegrityErr,
+			want: New(TransactionRetryable, WithMsg("transaction integrity constraint violation"), WithWrap(txIntegrityErr)),
+		},
+		{
+			name: "transaction-rollback-statement-completion-unknown",
+			e:    completionErr,
+			want: New(TransactionRetryable, WithMsg("statement completion unknown"), WithWrap(completionErr)),
+		},
+		{
+			name: "transaction-rollback-specific-code",
+			e:    serializationErr,
+			want: New(TransactionRetryable, WithMsg("could not serialize access"), WithWrap(serializationErr)),
+		},
+		{
+			name: "connection-failure",
+			e:    connFailureErr,
+			want: New(ConnectionFailure, WithMsg("connection failure"), WithWrap(connFailureErr)),
+		},
+		{
+			name: "connection-does-not-exist",
+			e:    noConnErr,
+			want: New(ConnectionFailure, WithMsg("connection does not exist"), WithWrap(noConnErr)),
+		},
+		{
+			name: "unhandled-class",
+			e:    syntaxErr,
+			want: syntaxErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(Convert(tt.e)))
+		})
+	}
+	t.Run("connection-failure-is-transient", func(t *testing.T) {
+		assert := assert.New(t)
+		err := Convert(connFailureErr).(*Err)
+		assert.True(err.Transient())
+		s, _ := status.FromError(err)
+		assert.Equal(codes.Unavailable, s.Code())
+	})
+}
+
+func TestConvertError_Constraint(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		e    error
+		want error
+	}{
+		{
+			name: "exclusion",
+			e:    errortest.NewPQError("23P01", "Key (during)=([2020-10-14,2020-10-15)) conflicts with existing key (during)=([2020-10-14,2020-10-16)).", "", "session_no_overlap", "session"),
+			want: New(NotSpecificIntegrity, WithMsg("session_no_overlap exclusion constraint failed"), WithDetails(map[string]string{"constraint": "session_no_overlap"})),
+		},
+		{
+			name: "exclusion-without-constraint",
+			e:    &pq.Error{Code: "23P01", Message: "conflicting key value violates exclusion constraint"},
+			want: New(NotSpecificIntegrity, WithMsg("conflicting key value violates exclusion constraint")),
+		},
+		{
+			name: "unique-named-index",
+			e:    errortest.NewPQError("23505", "Key (name)=(alice) already exists.", "", "iam_role_name_uq_idx", "iam_role"),
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name", "constraint": "iam_role_name_uq_idx"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "unique-expression-index",
+			e:    errortest.NewPQError("23505", "Key (lower(name::text))=(alice) already exists.", "", "iam_role_lower_name_uq_idx", "iam_role"),
+			want: New(NotUnique, WithRedactedMsg(`lower(name::text) "alice" is already in use`), WithDetails(map[string]string{"column": "lower(name::text)", "constraint": "iam_role_lower_name_uq_idx"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "unique-named-index-unparsable",
+			e:    errortest.NewPQError("23505", "duplicate key", "", "iam_role_name_uq_idx", "iam_role"),
+			want: New(NotUnique, WithRedactedMsg("duplicate key"), WithDetails(map[string]string{"constraint": "iam_role_name_uq_idx"}), WithWrap(ErrNotUnique)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := Convert(tt.e)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+			assert.True(errors.Is(err, ErrKindIntegrity))
+		})
+	}
+}
+
+// TestSetConvertMsgTransformer isn't parallel, since the transformer applies
+// to every call to Convert.
+func TestSetConvertMsgTransformer(t *testing.T) {
+	var codes []Code
+	SetConvertMsgTransformer(func(c Code, msg string) string {
+		codes = append(codes, c)
+		return strings.ReplaceAll(msg, "iam_", "")
+	})
+	defer SetConvertMsgTransformer(nil)
+	tests := []struct {
+		name string
+		e    error
+		opt  []Option
+		want error
+	}{
+		{
+			name: "unique",
+			e:    errortest.NewPQError("23505", "Key (iam_name)=(alice) already exists.", "", "", ""),
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "iam_name"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "not-null",
+			e:    errortest.NewPQError("23502", "", "iam_name", "", ""),
+			want: New(NotNull, WithMsg("name must not be empty"), WithWrap(ErrNotNull)),
+		},
+		{
+			name: "check",
+			e:    errortest.NewPQError("23514", "", "", "iam_name_must_be_lowercase", ""),
+			want: New(CheckConstraint, WithMsg("name_must_be_lowercase constraint failed"), WithWrap(ErrCheckConstraint)),
+		},
+		{
+			name: "caller-msg",
+			e:    errortest.NewPQError("23514", "", "", "iam_name_must_be_lowercase", ""),
+			opt:  []Option{WithMsg("iam_name is invalid")},
+			want: New(CheckConstraint, WithMsg("iam_name is invalid"), WithWrap(ErrCheckConstraint)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(Convert(tt.e, tt.opt...)))
+		})
+	}
+	assert.Equal(t, []Code{NotUnique, NotNull, CheckConstraint, CheckConstraint}, codes)
+
+	t.Run("removed", func(t *testing.T) {
+		assert := assert.New(t)
+		SetConvertMsgTransformer(nil)
+		err := Convert(errortest.NewPQError("23502", "", "iam_name", "", ""))
+		assert.Equal("iam_name must not be empty", err.(*Err).UserFacingMessage())
+	})
+}
+
+func TestConvertError_PgConn(t *testing.T) {
+	t.Parallel()
+	deadlockErr := &pgconn.PgError{
+		Code:    "40P01",
+		Message: "deadlock detected",
+	}
+	tests := []struct {
+		name string
+		e    error
+		want error
+	}{
+		{
+			name: "unique",
+			e: &pgconn.PgError{
+				Code:   "23505",
+				Detail: "Key (name)=(alice) already exists.",
+			},
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "unique-unparsable",
+			e: &pgconn.PgError{
+				Code:   "23505",
+				Detail: "duplicate key",
+			},
+			want: New(NotUnique, WithRedactedMsg("duplicate key"), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "not-null",
+			e: &pgconn.PgError{
+				Code:       "23502",
+				ColumnName: "name",
+			},
+			want: New(NotNull, WithMsg("name must not be empty"), WithWrap(ErrNotNull)),
+		},
+		{
+			name: "check",
+			e: &pgconn.PgError{
+				Code:           "23514",
+				ConstraintName: "name_must_be_lowercase",
+			},
+			want: New(CheckConstraint, WithMsg("name_must_be_lowercase constraint failed"), WithWrap(ErrCheckConstraint)),
+		},
+		{
+			name: "foreign-key",
+			e: &pgconn.PgError{
+				Code:           "23503",
+				ConstraintName: "iam_scope_parent_id_fkey",
+				TableName:      "iam_scope",
+			},
+			want: New(ForeignKeyViolation, WithMsg("iam_scope_parent_id_fkey constraint failed for iam_scope"), WithWrap(ErrForeignKeyViolation)),
+		},
+		{
+			name: "deadlock-detected",
+			e:    deadlockErr,
+			want: New(TransactionRetryable, WithMsg("deadlock detected"), WithWrap(deadlockErr)),
+		},
+		{
+			name: "wrapped-unique",
+			e: fmt.Errorf("unable to create: %w", &pgconn.PgError{
+				Code:   "23505",
+				Detail: "Key (name)=(alice) already exists.",
+			}),
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name"}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "not-convertible",
+			e:    &pgconn.PgError{Code: "XX000"},
+			want: &pgconn.PgError{Code: "XX000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := Convert(tt.e)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+		})
+	}
+}
+
+func TestConvertError_DebugDetails(t *testing.T) {
+	t.Parallel()
+	checkErr := errortest.NewPQError("23514", "", "", "name_must_be_lowercase", "iam_role")
+	checkErr.Where = "PL/pgSQL function lower_name() line 3 at RAISE"
+	syntaxErr := &pgconn.PgError{
+		Code:     "22P02",
+		Message:  `invalid input syntax for type integer: "one"`,
+		Hint:     "Use a numeric value.",
+		Position: 42,
+	}
+	uniqueErr := errortest.NewPQError("23505", "Key (name)=(alice) already exists.", "", "", "")
+	uniqueErr.Hint = "Choose another name."
+	tests := []struct {
+		name string
+		e    error
+		opt  []Option
+		want error
+	}{
+		{
+			name: "pq-where",
+			e:    checkErr,
+			want: New(CheckConstraint, WithMsg("name_must_be_lowercase constraint failed"), WithDetails(map[string]string{"where": "PL/pgSQL function lower_name() line 3 at RAISE"}), WithWrap(ErrCheckConstraint)),
+		},
+		{
+			name: "pgconn-hint-position",
+			e:    syntaxErr,
+			want: New(InvalidParameter, WithMsg(`invalid input syntax for type integer: "one"`), WithDetails(map[string]string{"hint": "Use a numeric value.", "position": "42"}), WithWrap(syntaxErr)),
+		},
+		{
+			name: "merged-with-convert-details",
+			e:    uniqueErr,
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name", "hint": "Choose another name."}), WithWrap(ErrNotUnique)),
+		},
+		{
+			name: "options-take-precedence",
+			e:    uniqueErr,
+			opt:  []Option{WithDetails(map[string]string{"hint": "Use the existing role."})},
+			want: New(NotUnique, WithRedactedMsg(`name "alice" is already in use`), WithDetails(map[string]string{"column": "name", "hint": "Use the existing role."}), WithWrap(ErrNotUnique)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := Convert(tt.e, tt.opt...)
+			assert.Equal(clearVolatile(tt.want), clearVolatile(err))
+		})
+	}
+}
+
+func TestParseUniqueDetail(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		detail     string
+		wantColumn string
+		wantValue  string
+		wantOk     bool
+	}{
+		{
+			detail:     "Key (name)=(alice) already exists.",
+			wantColumn: "name",
+			wantValue:  "alice",
+			wantOk:     true,
+		},
+		{
+			detail:     "Key (scope_id, name)=(p_1234567890, alice) already exists.",
+			wantColumn: "scope_id, name",
+			wantValue:  "p_1234567890, alice",
+			wantOk:     true,
+		},
+		{
+			detail:     "Key (lower(email::text))=(alice@example.com) already exists.",
+			wantColumn: "lower(email::text)",
+			wantValue:  "alice@example.com",
+			wantOk:     true,
+		},
+		{
+			detail:     "Key (name)=(alice (admin)) already exists.",
+			wantColumn: "name",
+			wantValue:  "alice (admin)",
+			wantOk:     true,
+		},
+		{
+			detail:     "Key (name)=() already exists.",
+			wantColumn: "name",
+			wantValue:  "",
+			wantOk:     true,
+		},
+		{
+			detail: "",
+		},
+		{
+			detail: "duplicate key value violates unique constraint",
+		},
+		{
+			detail: "Key (name)=(alice) is still referenced from table \"iam_role\".",
+		},
+		{
+			detail: "Key name=alice already exists.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.detail, func(t *testing.T) {
+			assert := assert.New(t)
+			column, value, ok := parseUniqueDetail(tt.detail)
+			assert.Equal(tt.wantOk, ok)
+			assert.Equal(tt.wantColumn, column)
+			assert.Equal(tt.wantValue, value)
+		})
+	}
+}
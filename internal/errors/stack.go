@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+)
+
+// stackDepth is the maximum number of frames captured for an Err's stack.
+const stackDepth = 32
+
+// stack represents the program counters of the call stack where an Err was
+// created.
+type stack []uintptr
+
+// callers returns the stack of the caller, skipping skip frames (see
+// runtime.Callers).
+func callers(skip int) stack {
+	var pcs [stackDepth]uintptr
+	n := runtime.Callers(skip, pcs[:])
+	return pcs[0:n]
+}
+
+// caller returns the file:line of the caller, skipping skip frames with the
+// same meaning as callers (so runtime.Callers is counted), or an empty string
+// when it can't be determined.
+func caller(skip int) string {
+	// runtime.Caller doesn't count itself, so skip one less frame
+	_, file, line, ok := runtime.Caller(skip - 1)
+	if !ok {
+		return ""
+	}
+	return file + ":" + strconv.Itoa(line)
+}
+
+// format writes every frame of the stack to w, one function per line
+// followed by its indented file:line.
+func (s stack) format(w io.Writer) {
+	if len(s) == 0 {
+		return
+	}
+	frames := runtime.CallersFrames(s)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+		if !more {
+			return
+		}
+	}
+}
+
+// Format satisfies the fmt.Formatter interface.  %+v writes the Err's Error()
+// followed by the stack captured when the Err was created, while %v and %s
+// write Error() and %q writes a quoted Error().  Any other verb writes Error()
+// like %v, so the error isn't lost.
+func (e *Err) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, e.Error())
+			if e != nil {
+				e.stack.format(s)
+			}
+			return
+		}
+		_, _ = io.WriteString(s, e.Error())
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Format(t *testing.T) {
+	t.Parallel()
+	t.Run("stack", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(InvalidParameter, WithOp("alice.Bob"), WithMsg("test msg"))
+		got := fmt.Sprintf("%+v", err)
+		assert.Contains(got, err.Error())
+		assert.Contains(got, "errors.TestError_Format")
+		assert.Contains(got, "stack_test.go")
+
+		// the first frame is the caller of New
+		f, _ := runtime.CallersFrames(err.(*Err).stack).Next()
+		assert.Contains(f.Function, "errors.TestError_Format")
+
+		assert.Equal(err.Error(), fmt.Sprintf("%v", err))
+		assert.Equal(err.Error(), fmt.Sprintf("%s", err))
+		assert.Equal(fmt.Sprintf("%q", err.Error()), fmt.Sprintf("%q", err))
+		assert.Equal(err.Error(), fmt.Sprintf("%d", err))
+		assert.Equal(err.Error(), fmt.Sprintf("%x", err))
+	})
+	t.Run("without-stack", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(InvalidParameter, WithMsg("test msg"), WithoutStack())
+		assert.Empty(err.(*Err).stack)
+		assert.Equal(err.Error(), fmt.Sprintf("%+v", err))
+		assert.Equal(err.Error(), fmt.Sprintf("%v", err))
+	})
+	t.Run("nil", func(t *testing.T) {
+		assert := assert.New(t)
+		var err *Err
+		assert.Equal("", fmt.Sprintf("%+v", err))
+		assert.Equal("", fmt.Sprintf("%v", err))
+	})
+}
+
+// TestCaptureCaller isn't parallel, since CaptureCaller applies to every Err
+// created while it's set.
+func TestCaptureCaller(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		assert := assert.New(t)
+		err := New(InvalidParameter, WithOp("alice.Bob")).(*Err)
+		assert.Empty(err.Caller)
+		assert.NotContains(err.DebugString(), "caller")
+		assert.NotContains(err.Args(), "caller")
+	})
+	t.Run("enabled", func(t *testing.T) {
+		assert := assert.New(t)
+		CaptureCaller = true
+		defer func() { CaptureCaller = false }()
+
+		_, file, line, _ := runtime.Caller(0)
+		err := New(InvalidParameter, WithOp("alice.Bob"), WithoutStack()).(*Err)
+		want := file + ":" + strconv.Itoa(line+1)
+		assert.Equal(want, err.Caller)
+		assert.True(strings.HasSuffix(err.Caller, "stack_test.go:"+strconv.Itoa(line+1)))
+		assert.Contains(err.DebugString(), "\n  caller: "+want)
+		assert.Equal([]interface{}{"code", "InvalidParameter", "kind", "parameter violation", "op", "alice.Bob", "caller", want}, err.Args())
+
+		_, _, line, _ = runtime.Caller(0)
+		wrapped := Wrap(err, "eve.Bob", Unknown).(*Err)
+		assert.Equal(file+":"+strconv.Itoa(line+1), wrapped.Caller)
+	})
+	t.Run("convert", func(t *testing.T) {
+		assert := assert.New(t)
+		CaptureCaller = true
+		defer func() { CaptureCaller = false }()
+
+		_, file, line, _ := runtime.Caller(0)
+		err := Convert(&pq.Error{Code: "23505"}).(*Err)
+		assert.Equal(file+":"+strconv.Itoa(line+1), err.Caller)
+
+		_, _, line, _ = runtime.Caller(0)
+		err = Convert(context.DeadlineExceeded).(*Err)
+		assert.Equal(file+":"+strconv.Itoa(line+1), err.Caller)
+
+		_, _, line, _ = runtime.Caller(0)
+		err = ConvertStrict(&pq.Error{Code: "23505"}).(*Err)
+		assert.Equal(file+":"+strconv.Itoa(line+1), err.Caller)
+
+		_, _, line, _ = runtime.Caller(0)
+		err = ConvertStrict(fmt.Errorf("test error")).(*Err)
+		assert.Equal(file+":"+strconv.Itoa(line+1), err.Caller)
+
+		// the first frame of the stack is the caller of Convert
+		err = Convert(&pq.Error{Code: "23505"}).(*Err)
+		f, _ := runtime.CallersFrames(err.stack).Next()
+		assert.Contains(f.Function, "errors.TestCaptureCaller")
+	})
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+// AssertError fails the test, and returns false, unless err contains an *Err
+// with the Code wantCode (see GetCode).
+func AssertError(t testing.TB, wantCode Code, err error) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected an error with code %s (%d), got nil", wantCode, uint32(wantCode))
+		return false
+	}
+	var e *Err
+	if !errors.As(err, &e) {
+		t.Errorf("expected an error with code %s (%d), got %T which doesn't contain an *Err: %v", wantCode, uint32(wantCode), err, err)
+		return false
+	}
+	if e.Code != wantCode {
+		t.Errorf("expected an error with code %s (%d), got code %s (%d): %v", wantCode, uint32(wantCode), e.Code, uint32(e.Code), err)
+		return false
+	}
+	return true
+}
+
+// AssertNoError fails the test, and returns false, if err isn't nil.
+func AssertNoError(t testing.TB, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return false
+	}
+	return true
+}
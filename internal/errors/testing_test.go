@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB is a testing.TB which records failures rather than failing the test
+type fakeTB struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		wantCode    Code
+		err         error
+		wantFailure string
+	}{
+		{
+			name:     "match",
+			wantCode: NotUnique,
+			err:      New(NotUnique, WithoutStack()),
+		},
+		{
+			name:     "wrapped",
+			wantCode: NotUnique,
+			err:      fmt.Errorf("test: %w", New(NotUnique, WithoutStack())),
+		},
+		{
+			name:        "nil",
+			wantCode:    NotUnique,
+			err:         nil,
+			wantFailure: "expected an error with code NotUnique (1002), got nil",
+		},
+		{
+			name:        "not-err",
+			wantCode:    NotUnique,
+			err:         errors.New("test error"),
+			wantFailure: "expected an error with code NotUnique (1002), got *errors.errorString which doesn't contain an *Err: test error",
+		},
+		{
+			name:        "wrong-code",
+			wantCode:    NotUnique,
+			err:         New(NotNull, WithoutStack()),
+			wantFailure: "expected an error with code NotUnique (1002), got code NotNull (1001): must not be empty (null) violation: integrity violation: error #1001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			tb := &fakeTB{}
+			got := AssertError(tb, tt.wantCode, tt.err)
+			if tt.wantFailure == "" {
+				assert.True(got)
+				assert.Empty(tb.failures)
+				return
+			}
+			assert.False(got)
+			assert.Equal([]string{tt.wantFailure}, tb.failures)
+		})
+	}
+}
+
+func TestAssertNoError(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	tb := &fakeTB{}
+	assert.True(AssertNoError(tb, nil))
+	assert.Empty(tb.failures)
+
+	assert.False(AssertNoError(tb, New(NotNull, WithoutStack())))
+	assert.Equal([]string{"unexpected error: must not be empty (null) violation: integrity violation: error #1001"}, tb.failures)
+}
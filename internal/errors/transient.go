@@ -0,0 +1,29 @@
+package errors
+
+// kindTransient provides a map of Kinds to whether their errors are transient
+// by default.  Kinds which are missing are permanent.
+var kindTransient = map[Kind]bool{
+	Other:       false,
+	Parameter:   false,
+	Integrity:   false,
+	Search:      false,
+	Transaction: true,
+	Interrupted: true,
+}
+
+// Transient returns true when the Err is transient (it may succeed if the
+// operation is tried again) and false when it's permanent.  It's the value
+// provided via WithTransient() to the Err or, when it wasn't provided, to the
+// first *Err in its chain of wrapped errors which was provided one.
+// Otherwise it's the default for the Err's Kind.
+func (e *Err) Transient() bool {
+	if e == nil {
+		return false
+	}
+	for _, w := range Flatten(e) {
+		if w.transient != nil {
+			return *w.transient
+		}
+	}
+	return kindTransient[e.Info().Kind]
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Transient(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  *Err
+		want bool
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "unknown",
+			err:  New(Unknown).(*Err),
+			want: false,
+		},
+		{
+			name: "parameter",
+			err:  New(InvalidParameter).(*Err),
+			want: false,
+		},
+		{
+			name: "search",
+			err:  New(RecordNotFound).(*Err),
+			want: false,
+		},
+		{
+			name: "integrity",
+			err:  New(NotUnique).(*Err),
+			want: false,
+		},
+		{
+			name: "transaction",
+			err:  New(TransactionRetryable).(*Err),
+			want: true,
+		},
+		{
+			name: "interrupted",
+			err:  New(Timeout).(*Err),
+			want: true,
+		},
+		{
+			name: "override-transient",
+			err:  New(RecordNotFound, WithTransient(true)).(*Err),
+			want: true,
+		},
+		{
+			name: "override-permanent",
+			err:  New(Timeout, WithTransient(false)).(*Err),
+			want: false,
+		},
+		{
+			name: "wrapped-override",
+			err:  New(Unknown, WithWrap(fmt.Errorf("wrapped: %w", New(RecordNotFound, WithTransient(true))))).(*Err),
+			want: true,
+		},
+		{
+			name: "wrapped-without-override",
+			err:  New(Unknown, WithWrap(New(Timeout))).(*Err),
+			want: false,
+		},
+		{
+			name: "outer-override-wins",
+			err:  New(Unknown, WithTransient(false), WithWrap(New(RecordNotFound, WithTransient(true)))).(*Err),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.err.Transient())
+		})
+	}
+	t.Run("every-kind", func(t *testing.T) {
+		for _, k := range []Kind{Other, Parameter, Integrity, Search, Transaction, Interrupted} {
+			_, ok := kindTransient[k]
+			assert.True(t, ok, "missing transient default for %s", k)
+		}
+	})
+}